@@ -13,40 +13,90 @@ import (
 	"syscall"
 	"time"
 
+	"kick-bot/internal/admin"
+	"kick-bot/internal/config"
+	"kick-bot/internal/dashboard"
+	"kick-bot/internal/engine"
 	"kick-bot/internal/kick"
 	"kick-bot/internal/logger"
 	"kick-bot/internal/proxy"
-	"kick-bot/internal/dashboard"
-	
-	"github.com/sirupsen/logrus"
-)
+	"kick-bot/internal/xlog"
 
-const (
-	defaultProxyFile  = "proxies.txt"
-	defaultBatchSize  = 100
-	defaultBatchDelay = 30 // seconds
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
 	// Define command line flags
 	var (
-		batchSize    = flag.Int("batch-size", defaultBatchSize, "Number of connections to start per batch")
-		batchDelay   = flag.Int("batch-delay", defaultBatchDelay, "Delay in seconds between batches")
+		batchSize    = flag.Int("batch-size", 0, "Number of connections to start per batch (default: config batch_size, or 100)")
+		batchDelay   = flag.Int("batch-delay", 0, "Delay in seconds between batches (default: config batch_delay, or 30)")
 		slowMode     = flag.Bool("slow", false, "Enable slow mode with batch processing and delays")
 		noDashboard  = flag.Bool("no-dashboard", false, "Disable dashboard and use verbose logging instead")
+		configPath   = flag.String("config", "config.yml", "Path to the YAML config file (ignored if missing)")
+		strictConfig = flag.Bool("strict-config", false, "Fail startup on unknown/misspelled config keys instead of warning")
+
+		proxyMinWeight     = flag.Float64("proxy-min-weight", 0, "Rebalancer weight floor a proxy can decay to before only being probed occasionally (default 0.1)")
+		proxyProbeInterval = flag.Duration("proxy-probe-interval", 0, "How often a proxy parked at the weight floor gets probed back in (default 30s)")
+
+		adminAddr     = flag.String("admin-addr", "", "Address to serve the admin HTTP API on, e.g. 127.0.0.1:9090 (default: config admin_addr, disabled if neither is set)")
+		adminHtpasswd = flag.String("admin-htpasswd", "", "Path to an htpasswd-style file of admin API credentials (required when the admin API is enabled)")
+
+		logFile       = flag.String("log-file", "", "Path to a rotated log file that receives full debug traces alongside the console output (disabled if empty)")
+		logMaxSizeMB  = flag.Int("log-max-size-mb", 100, "Max size in megabytes of the log file before it gets rotated")
+		logMaxBackups = flag.Int("log-max-backups", 5, "Max number of rotated log files to retain")
+		logMaxAgeDays = flag.Int("log-max-age-days", 28, "Max age in days to retain a rotated log file")
 	)
 	flag.Parse()
 
-	// Initialize logger with appropriate verbosity
-	log := logger.NewTextLogger()
+	// Load tunables from config.yml if present, otherwise fall back to defaults
+	cfg := config.Default()
+	if _, err := os.Stat(*configPath); err == nil {
+		loaded, err := config.Load(*configPath, *strictConfig)
+		if err != nil {
+			fmt.Printf("Failed to load config file %s: %v\n", *configPath, err)
+			os.Exit(1)
+		}
+		cfg = loaded
+	} else if *strictConfig {
+		fmt.Printf("--strict-config given but config file %s does not exist\n", *configPath)
+		os.Exit(1)
+	}
+
+	if *batchSize > 0 {
+		cfg.BatchSize = *batchSize
+	}
+	if *batchDelay > 0 {
+		cfg.BatchDelay = time.Duration(*batchDelay) * time.Second
+	}
+	if *adminAddr != "" {
+		cfg.AdminAddr = *adminAddr
+	}
+
+	// Initialize logger with appropriate verbosity. Console level mirrors the
+	// previous behaviour (verbose when the dashboard is disabled, quiet
+	// otherwise); when --log-file is set, a second sink writes full debug
+	// traces to a rotated file regardless of the console level.
+	consoleLevel := logrus.WarnLevel
 	if *noDashboard {
-		log.SetLevel(logrus.InfoLevel) // Verbose logging when dashboard is disabled
-	} else {
-		log.SetLevel(logrus.WarnLevel) // Only show warnings and errors in background
+		consoleLevel = logrus.InfoLevel
+	}
+
+	sinkCfg := logger.SinkConfig{
+		Mode:         logger.SinkConsole,
+		ConsoleLevel: consoleLevel,
 	}
-	
+	if *logFile != "" {
+		sinkCfg.Mode = logger.SinkMulti
+		sinkCfg.FileLevel = logrus.DebugLevel
+		sinkCfg.LogFile = *logFile
+		sinkCfg.MaxSizeMB = *logMaxSizeMB
+		sinkCfg.MaxBackups = *logMaxBackups
+		sinkCfg.MaxAgeDays = *logMaxAgeDays
+	}
+	log := logger.NewSink(sinkCfg)
+
 	if *slowMode && *noDashboard {
-		log.Infof("Slow mode enabled: batch size=%d, delay=%ds", *batchSize, *batchDelay)
+		log.Infof("Slow mode enabled: batch size=%d, delay=%v", cfg.BatchSize, cfg.BatchDelay)
 	}
 
 	if !*noDashboard {
@@ -58,14 +108,54 @@ func main() {
 
 	// Load proxies
 	proxyManager := proxy.NewProxyManager(log)
-	if err := proxyManager.LoadProxies(defaultProxyFile); err != nil {
+	proxyManager.ConfigureHealthCheck(cfg.IPCheckerURL, cfg.ProxyConnectTimeout)
+	proxyManager.SetRebalancerConfig(*proxyMinWeight, *proxyProbeInterval)
+	if err := proxyManager.LoadProxies(cfg.ProxyFile); err != nil {
 		fmt.Printf("Failed to load proxies: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Initialize Kick service
-	kickService := kick.NewService(proxyManager, log)
+	kickService := kick.NewService(proxyManager, log, cfg)
 
+	// Handle interrupt signals for graceful shutdown, shared by both the
+	// interactive single-channel flow and config-driven multi-target mode.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = xlog.NewContext(ctx, xlog.New(log))
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	var adminServer *admin.Server
+	if cfg.AdminAddr != "" {
+		if *adminHtpasswd == "" {
+			fmt.Println("--admin-addr given but --admin-htpasswd is required to protect it")
+			os.Exit(1)
+		}
+		srv, err := admin.NewServer(cfg.AdminAddr, *adminHtpasswd, proxyManager, cfg.ProxyFile, log)
+		if err != nil {
+			fmt.Printf("Failed to start admin API: %v\n", err)
+			os.Exit(1)
+		}
+		adminServer = srv
+	}
+
+	if len(cfg.Targets) > 0 {
+		runTargets(ctx, cfg, kickService, log, adminServer, *noDashboard, *slowMode)
+		return
+	}
+
+	runInteractive(ctx, cfg, kickService, log, adminServer, *noDashboard, *slowMode)
+}
+
+// runInteractive drives the original single-channel flow: prompt for a
+// channel and viewer count on stdin, then run one engine with one dashboard.
+func runInteractive(ctx context.Context, cfg *config.Config, kickService *kick.Service, log *logrus.Logger, adminServer *admin.Server, noDashboard, slowMode bool) {
 	// Get user input for channel
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("Channel link or name: ")
@@ -97,7 +187,7 @@ func main() {
 
 	// Get channel ID
 	fmt.Printf("Getting channel ID for: %s...\n", channelName)
-	channelID, err := kickService.GetChannelID(channelName)
+	channelID, err := kickService.GetChannelID(ctx, channelName)
 	if err != nil {
 		fmt.Printf("Failed to get channel ID: %v\n", err)
 		os.Exit(1)
@@ -105,39 +195,40 @@ func main() {
 
 	// Create dashboard if not disabled
 	var dash *dashboard.Dashboard
-	if !*noDashboard {
+	if !noDashboard {
 		dash = dashboard.NewDashboard(totalViewers, channelName, channelID)
+		dash.SetProxyManager(kickService.ProxyManager())
 		dash.Start()
 		defer dash.Stop()
 	}
 
-	// Set up context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle interrupt signals for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Build and start the engine that owns the connection workers
+	eng := engine.New(kickService, log, dash, engine.Options{
+		ChannelID:    channelID,
+		TotalViewers: totalViewers,
+		BatchSize:    cfg.BatchSize,
+		BatchDelay:   cfg.BatchDelay,
+		SlowMode:     slowMode,
+		NoDashboard:  noDashboard,
+	})
 
-	go func() {
-		<-sigChan
-		cancel()
-	}()
-
-	// Start connections based on mode
-	var wg sync.WaitGroup
+	if adminServer != nil {
+		adminServer.AddTarget(channelName, eng)
+		adminServer.Start()
+		defer adminServer.Shutdown(context.Background())
+	}
 
-	if *slowMode {
-		startConnectionsInBatches(ctx, &wg, totalViewers, *batchSize, *batchDelay, kickService, channelID, log, dash, *noDashboard)
-	} else {
-		startAllConnectionsSimultaneously(ctx, &wg, totalViewers, kickService, channelID, log, dash, *noDashboard)
+	if noDashboard {
+		log.Info("Press 'p' to pause, 'r' to resume, 'q' to quit")
 	}
+	eng.ListenStdinControls(ctx)
+	eng.Start(ctx)
+
+	// Wait for all connections to finish
+	eng.Wait()
 
-	// Wait for all goroutines to finish
-	wg.Wait()
-	
 	// Final summary
-	if !*noDashboard && dash != nil {
+	if !noDashboard && dash != nil {
 		stats := dash.GetStats()
 		fmt.Printf("\nFinal Summary:\n")
 		fmt.Printf("Total Connections: %d\n", stats.Total)
@@ -150,113 +241,66 @@ func main() {
 	}
 }
 
-// startConnectionsInBatches starts connections in batches with delays between them
-func startConnectionsInBatches(ctx context.Context, wg *sync.WaitGroup, totalViewers, batchSize, batchDelaySeconds int, kickService *kick.Service, channelID int, log *logrus.Logger, dash *dashboard.Dashboard, noDashboard bool) {
-	batchDelay := time.Duration(batchDelaySeconds) * time.Second
-	
-	for i := 0; i < totalViewers; i += batchSize {
-		// Check if context is cancelled before starting a new batch
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
+// runTargets drives config-driven multi-target mode: one engine and one
+// dashboard tab per cfg.Targets entry, all sharing kickService's proxy pool
+// but each capped by its own ProxyBudget. It bypasses the interactive
+// prompts entirely.
+func runTargets(ctx context.Context, cfg *config.Config, kickService *kick.Service, log *logrus.Logger, adminServer *admin.Server, noDashboard, slowMode bool) {
+	var tabbed *dashboard.TabbedDashboard
+	if !noDashboard {
+		tabbed = dashboard.NewTabbedDashboard(cfg.DashboardRefreshInterval)
+	}
 
-		end := i + batchSize
-		if end > totalViewers {
-			end = totalViewers
-		}
+	var wg sync.WaitGroup
+	for _, target := range cfg.Targets {
+		channelName := kick.ExtractChannelName(target.Channel)
 
-		if noDashboard {
-			batchNum := (i / batchSize) + 1
-			totalBatches := (totalViewers + batchSize - 1) / batchSize
-			log.Infof("Starting batch %d/%d (connections %d-%d)...", batchNum, totalBatches, i+1, end)
+		fmt.Printf("Getting channel ID for: %s...\n", channelName)
+		channelID, err := kickService.GetChannelID(ctx, channelName)
+		if err != nil {
+			fmt.Printf("Failed to get channel ID for %s: %v\n", channelName, err)
+			continue
 		}
 
-		// Start connections in this batch
-		for j := i; j < end; j++ {
-			wg.Add(1)
-			go startConnection(ctx, wg, j, kickService, channelID, log, dash, noDashboard)
+		var dash *dashboard.Dashboard
+		if tabbed != nil {
+			dash = dashboard.NewDashboard(target.Viewers, channelName, channelID)
+			dash.SetProxyManager(kickService.ProxyManager())
+			tabbed.AddTab(channelName, dash)
 		}
 
-		// Wait before starting next batch (except for the last batch)
-		if end < totalViewers {
-			if noDashboard {
-				log.Infof("Waiting %d seconds before next batch...", batchDelaySeconds)
-			}
-			
-			// Use a timer with context cancellation support
-			timer := time.NewTimer(batchDelay)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				return
-			case <-timer.C:
-				// Continue to next batch
-			}
-		}
-	}
-}
+		eng := engine.New(kickService, log, dash, engine.Options{
+			ChannelID:    channelID,
+			TotalViewers: target.Viewers,
+			BatchSize:    cfg.BatchSize,
+			BatchDelay:   cfg.BatchDelay,
+			SlowMode:     slowMode,
+			NoDashboard:  noDashboard,
+			ProxyBudget:  target.ProxyBudget,
+		})
 
-// startAllConnectionsSimultaneously starts all connections at once (original behavior)
-func startAllConnectionsSimultaneously(ctx context.Context, wg *sync.WaitGroup, totalViewers int, kickService *kick.Service, channelID int, log *logrus.Logger, dash *dashboard.Dashboard, noDashboard bool) {
-	if noDashboard {
-		log.Infof("Starting %d viewer connections...", totalViewers)
-	}
-	
-	for i := 0; i < totalViewers; i++ {
-		wg.Add(1)
-		go startConnection(ctx, wg, i, kickService, channelID, log, dash, noDashboard)
-	}
-}// startConnection handles a single connection (extracted from original code)
-func startConnection(ctx context.Context, wg *sync.WaitGroup, index int, kickService *kick.Service, channelID int, log *logrus.Logger, dash *dashboard.Dashboard, noDashboard bool) {
-	defer wg.Done()
+		if adminServer != nil {
+			adminServer.AddTarget(channelName, eng)
+		}
 
-	// Initialize connection status
-	if !noDashboard && dash != nil {
-		dash.UpdateConnection(index, dashboard.StatusConnecting, 1, "")
-	}
+		eng.Start(ctx)
 
-	// Get token for this connection
-	token, proxyURL, err := kickService.GetToken()
-	if err != nil {
-		if noDashboard {
-			log.WithError(err).Errorf("[%d] Failed to get token", index)
-		} else if dash != nil {
-			dash.UpdateConnection(index, dashboard.StatusFailed, 1, err.Error())
-		}
-		return
+		wg.Add(1)
+		go func(e *engine.Engine) {
+			defer wg.Done()
+			e.Wait()
+		}(eng)
 	}
 
-	if noDashboard {
-		log.Infof("[%d] Got token: %s using proxy: %s", index, token, proxyURL)
+	if tabbed != nil {
+		tabbed.Start()
+		defer tabbed.Stop()
 	}
-
-	// Create connection handler
-	handler := kick.NewConnectionHandler(index, channelID, token, proxyURL, log)
-
-	// Start connection with appropriate method
-	var connectionErr error
-	if noDashboard {
-		connectionErr = handler.Start(ctx)
-	} else if dash != nil {
-		connectionErr = handler.StartWithDashboard(ctx, dash)
+	if adminServer != nil {
+		adminServer.Start()
+		defer adminServer.Shutdown(context.Background())
 	}
 
-	// Handle connection result
-	if connectionErr != nil {
-		if connectionErr == context.Canceled {
-			if noDashboard {
-				log.Infof("[%d] Connection stopped due to shutdown", index)
-			}
-			// Don't mark as failed for shutdown
-			return
-		} else {
-			if noDashboard {
-				log.WithError(connectionErr).Errorf("[%d] Connection failed", index)
-			} else if dash != nil {
-				dash.UpdateConnection(index, dashboard.StatusFailed, 1, connectionErr.Error())
-			}
-		}
-	}
+	wg.Wait()
+	log.Info("All targets stopped. Exiting.")
 }
@@ -0,0 +1,317 @@
+// Package admin exposes a small HTTP/JSON control-plane for a running
+// kick-bot process — GET /stats, POST /reload-proxies, POST /scale,
+// POST /stop, and GET /connections — protected by htpasswd-style basic
+// auth. It's what turns the bot from a one-shot CLI into something that can
+// be safely exposed on a non-local interface and driven remotely, analogous
+// to frp's admin server.
+package admin
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"kick-bot/internal/dashboard"
+	"kick-bot/internal/engine"
+	"kick-bot/internal/proxy"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Target pairs a running Engine with the label admin clients use to address
+// it via the ?target= query param on /scale and /stop.
+type Target struct {
+	Name   string
+	Engine *engine.Engine
+}
+
+// Server is the admin HTTP API for a kick-bot process. It holds no business
+// logic of its own: every handler delegates to the proxy.ProxyManager and
+// engine.Engine instances it was configured with.
+type Server struct {
+	addr         string
+	logger       *logrus.Logger
+	proxyManager *proxy.ProxyManager
+	proxyFile    string
+	creds        map[string]string
+
+	mu      sync.RWMutex
+	targets []Target
+
+	httpServer *http.Server
+}
+
+// NewServer creates a Server listening on addr once Start is called.
+// htpasswdPath must point to an existing htpasswd-style file (see
+// loadHtpasswd); there is no way to run the admin API without auth
+// configured.
+func NewServer(addr, htpasswdPath string, pm *proxy.ProxyManager, proxyFile string, logger *logrus.Logger) (*Server, error) {
+	creds, err := loadHtpasswd(htpasswdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		addr:         addr,
+		logger:       logger,
+		proxyManager: pm,
+		proxyFile:    proxyFile,
+		creds:        creds,
+	}, nil
+}
+
+// loadHtpasswd reads a "user:hash" per line htpasswd-style file. Hashes
+// produced by `htpasswd -B` (bcrypt, $2a$/$2b$/$2y$ prefixed) are verified
+// with bcrypt in checkCredentials; any other value is treated as a
+// plaintext password, for local/dev use only.
+func loadHtpasswd(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	creds := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		creds[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading htpasswd file %s: %w", path, err)
+	}
+	if len(creds) == 0 {
+		return nil, fmt.Errorf("no credentials found in htpasswd file %s", path)
+	}
+
+	return creds, nil
+}
+
+// AddTarget registers eng under name so it shows up in /stats and can be
+// addressed by /scale and /stop.
+func (s *Server) AddTarget(name string, eng *engine.Engine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets = append(s.targets, Target{Name: name, Engine: eng})
+}
+
+// Start begins serving the admin API in the background. Call Shutdown to
+// stop it.
+func (s *Server) Start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.requireAuth(s.handleStats))
+	mux.HandleFunc("/reload-proxies", s.requireAuth(s.handleReloadProxies))
+	mux.HandleFunc("/scale", s.requireAuth(s.handleScale))
+	mux.HandleFunc("/stop", s.requireAuth(s.handleStop))
+	mux.HandleFunc("/connections", s.requireAuth(s.handleConnections))
+
+	s.httpServer = &http.Server{Addr: s.addr, Handler: mux}
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.WithError(err).Error("Admin server stopped unexpectedly")
+		}
+	}()
+	s.logger.Infof("Admin API listening on %s", s.addr)
+}
+
+// Shutdown gracefully stops the admin server, waiting for in-flight
+// requests to finish or ctx to expire.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// requireAuth wraps next with basic-auth enforcement against the loaded
+// htpasswd credentials.
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || !s.checkCredentials(user, pass) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="kick-bot admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) checkCredentials(user, pass string) bool {
+	hash, ok := s.creds[user]
+	if !ok {
+		return false
+	}
+	if strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(pass)) == 1
+}
+
+// statsResponse is the JSON body of GET /stats.
+type statsResponse struct {
+	Targets []targetStats         `json:"targets"`
+	Proxies []proxy.ProxySnapshot `json:"proxies"`
+}
+
+type targetStats struct {
+	Name  string                   `json:"name"`
+	State string                   `json:"state"`
+	Stats *dashboard.StatsSnapshot `json:"stats,omitempty"`
+}
+
+// handleStats returns each target's dashboard.GetStats() (when it has one)
+// plus per-proxy health/runtime stats from the rebalancer.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	resp := statsResponse{Proxies: s.proxyManager.Snapshot()}
+
+	for _, t := range s.targetsSnapshot() {
+		ts := targetStats{Name: t.Name, State: t.Engine.State().String()}
+		if dash := t.Engine.Dashboard(); dash != nil {
+			snap := dash.GetStats()
+			ts.Stats = &snap
+		}
+		resp.Targets = append(resp.Targets, ts)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleReloadProxies hot-reloads the proxy file without restarting the
+// process, reconciling the new list with the live ProxyManager (see
+// proxy.ProxyManager.ReloadProxies).
+func (s *Server) handleReloadProxies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.proxyManager.ReloadProxies(s.proxyFile); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"proxies_loaded": s.proxyManager.Count()})
+}
+
+// handleScale applies ?delta= (e.g. "+50") to ?target=, or the sole running
+// target when only one is registered. See engine.Engine.Scale for why
+// negative deltas aren't supported.
+func (s *Server) handleScale(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	deltaStr := r.URL.Query().Get("delta")
+	delta, err := strconv.Atoi(deltaStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid delta %q: %v", deltaStr, err), http.StatusBadRequest)
+		return
+	}
+
+	target, err := s.resolveTarget(r.URL.Query().Get("target"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := target.Engine.Scale(delta); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "scaled"})
+}
+
+// handleStop stops ?target=, or every registered target when it's omitted.
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	targetName := r.URL.Query().Get("target")
+	stopped := 0
+	for _, t := range s.targetsSnapshot() {
+		if targetName != "" && t.Name != targetName {
+			continue
+		}
+		go t.Engine.Stop()
+		stopped++
+	}
+
+	if targetName != "" && stopped == 0 {
+		http.Error(w, fmt.Sprintf("unknown target %q", targetName), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"stopped": stopped})
+}
+
+// connectionLine is one line of the GET /connections NDJSON stream.
+type connectionLine struct {
+	Target     string                   `json:"target"`
+	Connection dashboard.ConnectionInfo `json:"connection"`
+}
+
+// handleConnections streams every target's per-connection state as
+// newline-delimited JSON, one object per connection.
+func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+
+	for _, t := range s.targetsSnapshot() {
+		dash := t.Engine.Dashboard()
+		if dash == nil {
+			continue
+		}
+		snap := dash.GetStats()
+		for _, conn := range snap.Connections {
+			enc.Encode(connectionLine{Target: t.Name, Connection: conn})
+		}
+	}
+}
+
+func (s *Server) targetsSnapshot() []Target {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	targets := make([]Target, len(s.targets))
+	copy(targets, s.targets)
+	return targets
+}
+
+func (s *Server) resolveTarget(name string) (Target, error) {
+	targets := s.targetsSnapshot()
+
+	if name == "" {
+		if len(targets) == 1 {
+			return targets[0], nil
+		}
+		return Target{}, fmt.Errorf("target query param is required when more than one target is running")
+	}
+	for _, t := range targets {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Target{}, fmt.Errorf("unknown target %q", name)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
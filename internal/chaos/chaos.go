@@ -0,0 +1,103 @@
+// Package chaos provides opt-in, in-process fault injection for validating
+// that retry loops, the proxy health-checker, and the round-robin failure
+// tracker all behave correctly under adverse network conditions, without
+// needing real broken proxies. It wraps an http.RoundTripper the same way
+// toxiproxy wraps a TCP connection with toxics, except everything happens
+// inside this process.
+package chaos
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Config controls which faults get injected and how often. All
+// probabilities are independent per-request checks in [0, 1].
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+
+	// DropProbability is the chance a request fails as if the connection
+	// died mid-flight.
+	DropProbability float64 `yaml:"drop_probability"`
+
+	// LatencyMeanMs/LatencyStdDevMs describe a normal distribution of
+	// injected latency, in milliseconds. Zero mean disables latency injection.
+	LatencyMeanMs   float64 `yaml:"latency_mean_ms"`
+	LatencyStdDevMs float64 `yaml:"latency_stddev_ms"`
+
+	// ForcedStatusProbability is the chance a request gets a synthetic
+	// response with a status code drawn from ForcedStatuses instead of
+	// actually being sent.
+	ForcedStatusProbability float64 `yaml:"forced_status_probability"`
+	ForcedStatuses          []int   `yaml:"forced_statuses"`
+
+	// DeadConnProbability is the chance a request fails as if the proxy's
+	// TCP connection could never be established.
+	DeadConnProbability float64 `yaml:"dead_conn_probability"`
+}
+
+// Transport wraps an http.RoundTripper with Config's fault injection.
+type Transport struct {
+	next http.RoundTripper
+	cfg  Config
+	rng  *rand.Rand
+}
+
+// WrapTransport wraps next with chaos fault injection, or returns next
+// unmodified if cfg.Enabled is false.
+func WrapTransport(next http.RoundTripper, cfg Config) http.RoundTripper {
+	if !cfg.Enabled {
+		return next
+	}
+	return &Transport{
+		next: next,
+		cfg:  cfg,
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// RoundTrip injects faults according to t.cfg before (maybe) delegating to
+// the wrapped transport.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.rng.Float64() < t.cfg.DeadConnProbability {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("chaos: simulated dead proxy connection")}
+	}
+
+	if t.cfg.LatencyMeanMs > 0 {
+		delayMs := t.rng.NormFloat64()*t.cfg.LatencyStdDevMs + t.cfg.LatencyMeanMs
+		if delayMs > 0 {
+			time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		}
+	}
+
+	if t.rng.Float64() < t.cfg.DropProbability {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("chaos: simulated dropped request")}
+	}
+
+	if len(t.cfg.ForcedStatuses) > 0 && t.rng.Float64() < t.cfg.ForcedStatusProbability {
+		status := t.cfg.ForcedStatuses[t.rng.Intn(len(t.cfg.ForcedStatuses))]
+		return syntheticResponse(req, status), nil
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// syntheticResponse builds a minimal, empty-bodied *http.Response carrying
+// the given status code, as if the server had actually replied with it.
+func syntheticResponse(req *http.Request, status int) *http.Response {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}
+}
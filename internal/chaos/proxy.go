@@ -0,0 +1,18 @@
+package chaos
+
+import (
+	"net/http"
+
+	"kick-bot/internal/proxy"
+)
+
+// WrapProxyTransport builds p's normal transport and wraps it with cfg's
+// fault injection, so callers can flip a config flag instead of threading a
+// chaos-aware transport through every call site.
+func WrapProxyTransport(p proxy.Proxy, cfg Config) (http.RoundTripper, error) {
+	transport, err := p.GetTransport()
+	if err != nil {
+		return nil, err
+	}
+	return WrapTransport(transport, cfg), nil
+}
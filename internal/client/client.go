@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 
+	"kick-bot/internal/chaos"
+	"kick-bot/internal/config"
 	"kick-bot/internal/proxy"
 
 	"github.com/sirupsen/logrus"
@@ -18,24 +19,38 @@ type Client struct {
 	httpClient *http.Client
 	proxy      proxy.Proxy
 	logger     *logrus.Logger
+	userAgent  string
 }
 
-// NewClient creates a new HTTP client with the given proxy
-func NewClient(p proxy.Proxy, logger *logrus.Logger) (*Client, error) {
-	transport, err := p.GetTransport()
+// NewClient creates a new HTTP client with the given proxy. cfg may be nil,
+// in which case config.Default() is used. When cfg.Chaos is enabled, the
+// proxy's transport is wrapped with fault injection for resilience testing.
+func NewClient(p proxy.Proxy, logger *logrus.Logger, cfg *config.Config) (*Client, error) {
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
+	var transport http.RoundTripper
+	var err error
+	if cfg.Chaos.Enabled {
+		transport, err = chaos.WrapProxyTransport(p, cfg.Chaos)
+	} else {
+		transport, err = p.GetTransport()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transport: %w", err)
 	}
 
 	httpClient := &http.Client{
 		Transport: transport,
-		Timeout:   30 * time.Second,
+		Timeout:   cfg.HTTPTimeout,
 	}
 
 	return &Client{
 		httpClient: httpClient,
 		proxy:      p,
 		logger:     logger,
+		userAgent:  cfg.UserAgent,
 	}, nil
 }
 
@@ -53,7 +68,7 @@ func (c *Client) Get(url string, headers map[string]string) (*http.Response, err
 	}
 
 	// Set User-Agent to mimic Firefox
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:135.0) Gecko/20100101 Firefox/135.0")
+	req.Header.Set("User-Agent", c.userAgent)
 
 	c.logger.Debugf("Making GET request to %s using proxy %s:%s", url, c.proxy.IP, c.proxy.Port)
 
@@ -93,7 +108,7 @@ func (c *Client) Post(url string, data interface{}, headers map[string]string) (
 
 	// Set default headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:135.0) Gecko/20100101 Firefox/135.0")
+	req.Header.Set("User-Agent", c.userAgent)
 
 	c.logger.Debugf("Making POST request to %s using proxy %s:%s", url, c.proxy.IP, c.proxy.Port)
 
@@ -2,27 +2,34 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
+	"kick-bot/internal/config"
 	"kick-bot/internal/proxy"
+	"kick-bot/internal/xlog"
 
 	fhttp "github.com/bogdanfinn/fhttp"
 	tls_client "github.com/bogdanfinn/tls-client"
-	"github.com/sirupsen/logrus"
 )
 
 // TLSClient represents a TLS-aware HTTP client that can bypass Cloudflare
 type TLSClient struct {
 	httpClient tls_client.HttpClient
 	proxy      proxy.Proxy
-	logger     *logrus.Logger
+	userAgent  string
 }
 
-// NewTLSClient creates a new TLS client with browser impersonation
-func NewTLSClient(p proxy.Proxy, logger *logrus.Logger) (*TLSClient, error) {
+// NewTLSClient creates a new TLS client with browser impersonation. cfg may
+// be nil, in which case config.Default() is used.
+func NewTLSClient(p proxy.Proxy, cfg *config.Config) (*TLSClient, error) {
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
 	jar := tls_client.NewCookieJar()
 
 	options := []tls_client.HttpClientOption{
@@ -39,15 +46,19 @@ func NewTLSClient(p proxy.Proxy, logger *logrus.Logger) (*TLSClient, error) {
 	return &TLSClient{
 		httpClient: client,
 		proxy:      p,
-		logger:     logger,
+		userAgent:  cfg.UserAgent,
 	}, nil
 }
 
-// Get performs a GET request with TLS fingerprinting to bypass Cloudflare
-func (c *TLSClient) Get(url string, headers map[string]string) (*http.Response, error) {
+// Get performs a GET request with TLS fingerprinting to bypass Cloudflare.
+// ctx's xlog.Logger (see xlog.FromContext) is tagged with the proxy used
+// before logging the request.
+func (c *TLSClient) Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	xl := xlog.FromContext(ctx).WithProxy(c.proxy.Key())
+
 	req, err := fhttp.NewRequest("GET", url, nil)
 	if err != nil {
-		c.logger.WithError(err).Errorf("Failed to create TLS GET request for URL: %s", url)
+		xl.WithError(err).Errorf("Failed to create TLS GET request for URL: %s", url)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -59,28 +70,31 @@ func (c *TLSClient) Get(url string, headers map[string]string) (*http.Response,
 		req.Header.Set(key, value)
 	}
 
-	c.logger.Debugf("Making TLS GET request to %s using proxy %s:%s", url, c.proxy.IP, c.proxy.Port)
+	xl.Debugf("Making TLS GET request to %s", url)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		c.logger.WithError(err).Errorf("TLS GET request failed for URL: %s", url)
+		xl.WithError(err).Errorf("TLS GET request failed for URL: %s", url)
 		return nil, fmt.Errorf("TLS request failed: %w", err)
 	}
 
-	c.logger.Debugf("TLS GET request successful, status: %d", resp.StatusCode)
+	xl.Debugf("TLS GET request successful, status: %d", resp.StatusCode)
 
 	// Convert fhttp.Response to net/http.Response for compatibility
 	return c.convertResponse(resp), nil
 }
 
-// Post performs a POST request with TLS fingerprinting
-func (c *TLSClient) Post(url string, data interface{}, headers map[string]string) (*http.Response, error) {
+// Post performs a POST request with TLS fingerprinting. See Get for how ctx
+// is used for logging.
+func (c *TLSClient) Post(ctx context.Context, url string, data interface{}, headers map[string]string) (*http.Response, error) {
+	xl := xlog.FromContext(ctx).WithProxy(c.proxy.Key())
+
 	var body io.Reader
 
 	if data != nil {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
-			c.logger.WithError(err).Error("Failed to marshal JSON data")
+			xl.WithError(err).Error("Failed to marshal JSON data")
 			return nil, fmt.Errorf("failed to marshal data: %w", err)
 		}
 		body = bytes.NewBuffer(jsonData)
@@ -88,7 +102,7 @@ func (c *TLSClient) Post(url string, data interface{}, headers map[string]string
 
 	req, err := fhttp.NewRequest("POST", url, body)
 	if err != nil {
-		c.logger.WithError(err).Errorf("Failed to create TLS POST request for URL: %s", url)
+		xl.WithError(err).Errorf("Failed to create TLS POST request for URL: %s", url)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -101,15 +115,15 @@ func (c *TLSClient) Post(url string, data interface{}, headers map[string]string
 		req.Header.Set(key, value)
 	}
 
-	c.logger.Debugf("Making TLS POST request to %s using proxy %s:%s", url, c.proxy.IP, c.proxy.Port)
+	xl.Debugf("Making TLS POST request to %s", url)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		c.logger.WithError(err).Errorf("TLS POST request failed for URL: %s", url)
+		xl.WithError(err).Errorf("TLS POST request failed for URL: %s", url)
 		return nil, fmt.Errorf("TLS request failed: %w", err)
 	}
 
-	c.logger.Debugf("TLS POST request successful, status: %d", resp.StatusCode)
+	xl.Debugf("TLS POST request successful, status: %d", resp.StatusCode)
 
 	// Convert fhttp.Response to net/http.Response for compatibility
 	return c.convertResponse(resp), nil
@@ -117,7 +131,7 @@ func (c *TLSClient) Post(url string, data interface{}, headers map[string]string
 
 // setFirefoxLikeHeaders sets headers that mimic Firefox browser
 func (c *TLSClient) setFirefoxLikeHeaders(req *fhttp.Request) {
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:135.0) Gecko/20100101 Firefox/135.0")
+	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Accept", "application/json, text/plain, */*")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	req.Header.Set("Cache-Control", "no-cache")
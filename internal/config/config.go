@@ -0,0 +1,260 @@
+// Package config loads runtime tunables for kick-bot from a YAML file,
+// replacing the constants that used to be scattered across internal/kick,
+// internal/client, and internal/proxy.
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"kick-bot/internal/chaos"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProxyPoolSource describes one source of proxies to merge into the
+// ProxyManager: either a local file (proxy_pool_ours) or a remote URL to
+// fetch a proxy list from (proxy_pool_thirdparty).
+type ProxyPoolSource struct {
+	Path     string  `yaml:"path,omitempty"`
+	URL      string  `yaml:"url,omitempty"`
+	Username string  `yaml:"username,omitempty"`
+	Password string  `yaml:"password,omitempty"`
+	Weight   float64 `yaml:"weight,omitempty"`
+}
+
+// RequestDelay holds the artificial delays inserted between requests to
+// avoid tripping Kick's rate limiting.
+type RequestDelay struct {
+	ChannelID time.Duration `yaml:"channel_id"`
+	Token     time.Duration `yaml:"token"`
+}
+
+// Target describes one channel to drive fake views against when running in
+// config-driven multi-target mode (see Config.Targets). Each target gets its
+// own dashboard tab and engine, but shares the process's single proxy pool.
+type Target struct {
+	Channel string `yaml:"channel"`
+	Viewers int    `yaml:"viewers"`
+
+	// ProxyBudget caps how many proxies this target's engine may have
+	// in flight at once, so one busy target can't starve the others out of
+	// the shared pool. 0 means unlimited (bounded only by Viewers).
+	ProxyBudget int `yaml:"proxy_budget,omitempty"`
+}
+
+// Config holds every runtime-tunable value for the bot.
+type Config struct {
+	MaxRetries  int    `yaml:"max_retries"`
+	BaseURL     string `yaml:"base_url"`
+	APIBaseURL  string `yaml:"api_base_url"`
+	WSTokenURL  string `yaml:"ws_token_url"`
+	ClientToken string `yaml:"client_token"`
+
+	HTTPTimeout time.Duration `yaml:"http_timeout"`
+	UserAgent   string        `yaml:"user_agent"`
+	// UserAgents, when non-empty, is a pool the HTTP client picks from at
+	// random instead of always sending UserAgent.
+	UserAgents   []string     `yaml:"user_agents,omitempty"`
+	RequestDelay RequestDelay `yaml:"request_delay"`
+
+	DashboardRefreshInterval time.Duration `yaml:"dashboard_refresh_interval"`
+
+	IPCheckerURL        string        `yaml:"ip_checker_url"`
+	ProxyConnectTimeout time.Duration `yaml:"proxy_connect_timeout"`
+	ThirdPartyTestURLs  []string      `yaml:"thirdparty_test_urls"`
+
+	ProxyPoolOurs       []ProxyPoolSource `yaml:"proxy_pool_ours"`
+	ProxyPoolThirdParty []ProxyPoolSource `yaml:"proxy_pool_thirdparty"`
+	// ProxyFile is the flat proxy list loaded via proxy.ProxyManager.LoadProxies,
+	// kept separate from ProxyPoolOurs/ProxyPoolThirdParty which feed a
+	// different loader path.
+	ProxyFile string `yaml:"proxy_file,omitempty"`
+
+	BatchSize  int           `yaml:"batch_size,omitempty"`
+	BatchDelay time.Duration `yaml:"batch_delay,omitempty"`
+
+	// AdminAddr, when set, starts the admin HTTP API on this address
+	// (see internal/admin). Empty disables it.
+	AdminAddr string `yaml:"admin_addr,omitempty"`
+
+	// Targets, when non-empty, switches main.go into config-driven
+	// multi-target mode: one engine and dashboard tab per entry instead of
+	// the interactive single-channel prompts.
+	Targets []Target `yaml:"targets,omitempty"`
+
+	// Chaos enables in-process fault injection for resilience testing. It's
+	// disabled by default and should only be turned on in dev/test runs.
+	Chaos chaos.Config `yaml:"chaos"`
+}
+
+// Default returns the configuration that matches today's hardcoded
+// behavior, used whenever a value isn't set in config.yml.
+func Default() *Config {
+	return &Config{
+		MaxRetries:  5,
+		BaseURL:     "https://kick.com",
+		APIBaseURL:  "https://kick.com/api/v2",
+		WSTokenURL:  "https://websockets.kick.com/viewer/v1/token",
+		ClientToken: "e1393935a959b4020a4491574f6490129f678acdaa92760471263db43487f823",
+
+		HTTPTimeout: 30 * time.Second,
+		UserAgent:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:135.0) Gecko/20100101 Firefox/135.0",
+		RequestDelay: RequestDelay{
+			ChannelID: 200 * time.Millisecond,
+			Token:     300 * time.Millisecond,
+		},
+
+		DashboardRefreshInterval: 1 * time.Second,
+
+		IPCheckerURL:        "https://api.ipify.org",
+		ProxyConnectTimeout: 10 * time.Second,
+
+		ProxyFile:  "proxies.txt",
+		BatchSize:  100,
+		BatchDelay: 30 * time.Second,
+	}
+}
+
+// Load reads and parses a YAML config file at path, filling any field left
+// unset with its Default() value, then layers KICKBOT_* environment
+// overrides on top (see applyEnvOverrides).
+//
+// When strict is true, unknown or misspelled keys fail the load with a
+// precise "line N" error instead of being silently ignored — borrowed from
+// frp's --strict-config, for catching typos in long target lists before
+// they burn a run.
+func Load(path string, strict bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	cfg := Default()
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(strict)
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.applyDefaults(); err != nil {
+		return nil, err
+	}
+
+	applyEnvOverrides(cfg)
+
+	return cfg, nil
+}
+
+// applyDefaults fills in any zero-valued field with the built-in default,
+// since a partially-specified config.yml shouldn't zero out the rest.
+func (c *Config) applyDefaults() error {
+	d := Default()
+
+	if c.MaxRetries == 0 {
+		c.MaxRetries = d.MaxRetries
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = d.BaseURL
+	}
+	if c.APIBaseURL == "" {
+		c.APIBaseURL = d.APIBaseURL
+	}
+	if c.WSTokenURL == "" {
+		c.WSTokenURL = d.WSTokenURL
+	}
+	if c.ClientToken == "" {
+		c.ClientToken = d.ClientToken
+	}
+	if c.HTTPTimeout == 0 {
+		c.HTTPTimeout = d.HTTPTimeout
+	}
+	if c.UserAgent == "" {
+		c.UserAgent = d.UserAgent
+	}
+	if c.RequestDelay.ChannelID == 0 {
+		c.RequestDelay.ChannelID = d.RequestDelay.ChannelID
+	}
+	if c.RequestDelay.Token == 0 {
+		c.RequestDelay.Token = d.RequestDelay.Token
+	}
+	if c.DashboardRefreshInterval == 0 {
+		c.DashboardRefreshInterval = d.DashboardRefreshInterval
+	}
+	if c.IPCheckerURL == "" {
+		c.IPCheckerURL = d.IPCheckerURL
+	}
+	if c.ProxyConnectTimeout == 0 {
+		c.ProxyConnectTimeout = d.ProxyConnectTimeout
+	}
+	if c.ProxyFile == "" {
+		c.ProxyFile = d.ProxyFile
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = d.BatchSize
+	}
+	if c.BatchDelay == 0 {
+		c.BatchDelay = d.BatchDelay
+	}
+
+	return nil
+}
+
+// envOverrides maps a KICKBOT_* environment variable to the setter applied
+// when it's present, so a deployment can tweak a tunable without touching
+// config.yml. Kept as a table instead of a string switch so adding one is a
+// one-line change.
+var envOverrides = map[string]func(c *Config, value string) error{
+	"KICKBOT_BATCH_SIZE": func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("KICKBOT_BATCH_SIZE: %w", err)
+		}
+		c.BatchSize = n
+		return nil
+	},
+	"KICKBOT_BATCH_DELAY": func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("KICKBOT_BATCH_DELAY: %w", err)
+		}
+		c.BatchDelay = d
+		return nil
+	},
+	"KICKBOT_PROXY_FILE": func(c *Config, value string) error {
+		c.ProxyFile = value
+		return nil
+	},
+	"KICKBOT_ADMIN_ADDR": func(c *Config, value string) error {
+		c.AdminAddr = value
+		return nil
+	},
+	"KICKBOT_MAX_RETRIES": func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("KICKBOT_MAX_RETRIES: %w", err)
+		}
+		c.MaxRetries = n
+		return nil
+	},
+}
+
+// applyEnvOverrides layers KICKBOT_* environment variables on top of cfg.
+// A malformed value is logged to stderr and otherwise ignored, so a bad
+// override can't crash a run that the config file alone would have started
+// fine.
+func applyEnvOverrides(cfg *Config) {
+	for name, set := range envOverrides {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := set(cfg, value); err != nil {
+			fmt.Fprintf(os.Stderr, "config: ignoring invalid %s: %v\n", name, err)
+		}
+	}
+}
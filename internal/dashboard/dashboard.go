@@ -7,6 +7,8 @@ import (
 	"runtime"
 	"sync"
 	"time"
+
+	"kick-bot/internal/proxy"
 )
 
 // ConnectionStatus represents the status of a connection
@@ -84,6 +86,10 @@ type ConnectionInfo struct {
 type Dashboard struct {
 	stats *ConnectionStats
 	done  chan bool
+
+	// proxyManager is optional (see SetProxyManager); when set, render shows
+	// a circuit-breaker summary line alongside the connection-status counters.
+	proxyManager *proxy.ProxyManager
 }
 
 // NewDashboard creates a new dashboard instance
@@ -110,6 +116,22 @@ func (d *Dashboard) Stop() {
 	close(d.done)
 }
 
+// SetProxyManager wires pm into the dashboard so render can show a
+// circuit-breaker summary (standby/tripped/recovering proxy counts)
+// alongside the connection-status counters.
+func (d *Dashboard) SetProxyManager(pm *proxy.ProxyManager) {
+	d.proxyManager = pm
+}
+
+// AddCapacity increases the dashboard's Total connection count by delta,
+// used when an engine scales up via the admin API after the dashboard was
+// already constructed with the original viewer count.
+func (d *Dashboard) AddCapacity(delta int) {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	d.stats.Total += delta
+}
+
 // UpdateConnection updates the status of a specific connection
 func (d *Dashboard) UpdateConnection(index int, status ConnectionStatus, attempts int, lastError string) {
 	d.stats.mu.Lock()
@@ -226,7 +248,13 @@ func (d *Dashboard) render() {
 		d.stats.Connected, d.stats.Connecting, d.stats.Retrying)
 	fmt.Printf("║ 🔴 Failed: %-15d │ Last Update: %-27s ║\n",
 		d.stats.Failed, d.stats.LastUpdate.Format("15:04:05"))
-	
+
+	if d.proxyManager != nil {
+		standby, tripped, recovering := d.proxyManager.CircuitSummary()
+		fmt.Printf("║ Circuit Breakers: %-4d standby │ %-4d tripped │ %-4d recovering%*s║\n",
+			standby, tripped, recovering, 15, "")
+	}
+
 	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════╣")
 	
 	// Recent activity (show last few connection changes)
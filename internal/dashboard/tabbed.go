@@ -0,0 +1,97 @@
+package dashboard
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RenderOnce repaints the dashboard a single time, outside of its own
+// updateLoop ticker. TabbedDashboard uses this to drive rendering itself so
+// multiple dashboards sharing one terminal don't race each other's Start.
+func (d *Dashboard) RenderOnce() {
+	d.render()
+}
+
+// TabbedDashboard cycles a fixed-width dashboard display across several
+// targets running in the same process, one tab at a time, instead of each
+// target's Dashboard repainting the terminal independently.
+type TabbedDashboard struct {
+	mu     sync.Mutex
+	labels []string
+	tabs   []*Dashboard
+	active int
+
+	cycle time.Duration
+	done  chan bool
+}
+
+// NewTabbedDashboard creates a TabbedDashboard that spends cycle on each tab
+// before advancing to the next.
+func NewTabbedDashboard(cycle time.Duration) *TabbedDashboard {
+	return &TabbedDashboard{
+		cycle: cycle,
+		done:  make(chan bool),
+	}
+}
+
+// AddTab registers d under label. Tabs are shown in the order they're added.
+// d's own Start/Stop should not be called: TabbedDashboard drives its
+// rendering directly via RenderOnce.
+func (t *TabbedDashboard) AddTab(label string, d *Dashboard) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.labels = append(t.labels, label)
+	t.tabs = append(t.tabs, d)
+}
+
+// Start begins cycling through tabs, rendering one per tick.
+func (t *TabbedDashboard) Start() {
+	go t.loop()
+}
+
+// Stop stops the cycling loop.
+func (t *TabbedDashboard) Stop() {
+	close(t.done)
+}
+
+func (t *TabbedDashboard) loop() {
+	ticker := time.NewTicker(t.cycle)
+	defer ticker.Stop()
+
+	t.renderActive()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			t.advance()
+			t.renderActive()
+		}
+	}
+}
+
+func (t *TabbedDashboard) advance() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.tabs) == 0 {
+		return
+	}
+	t.active = (t.active + 1) % len(t.tabs)
+}
+
+func (t *TabbedDashboard) renderActive() {
+	t.mu.Lock()
+	if len(t.tabs) == 0 {
+		t.mu.Unlock()
+		return
+	}
+	label := t.labels[t.active]
+	dash := t.tabs[t.active]
+	idx := t.active
+	total := len(t.tabs)
+	t.mu.Unlock()
+
+	fmt.Printf("Tab %d/%d: %s\n", idx+1, total, label)
+	dash.RenderOnce()
+}
@@ -0,0 +1,363 @@
+// Package engine owns the worker goroutines that drive each fake-view
+// connection and exposes a small state machine so the bot can be paused,
+// resumed, and stopped cleanly instead of only reacting to Ctrl+C.
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"kick-bot/internal/dashboard"
+	"kick-bot/internal/kick"
+	"kick-bot/internal/xlog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// State is one of the lifecycle states an Engine moves through.
+type State uint32
+
+const (
+	StateNew State = iota
+	StateRunning
+	StatePaused
+	StateStopped
+)
+
+// String implements fmt.Stringer for State so it can be logged directly.
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateRunning:
+		return "running"
+	case StatePaused:
+		return "paused"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Options configures a single Engine run.
+type Options struct {
+	ChannelID    int
+	TotalViewers int
+	BatchSize    int
+	BatchDelay   time.Duration
+	SlowMode     bool
+	NoDashboard  bool
+
+	// ProxyBudget caps how many connections this engine may hold open at
+	// once, so a single target can't monopolize the shared proxy pool when
+	// several engines are running concurrently (see config.Target). 0 means
+	// unlimited, bounded only by TotalViewers.
+	ProxyBudget int
+}
+
+// Engine owns the goroutines spawning and running fake-view connections for
+// one channel, and tracks their aggregate lifecycle state.
+type Engine struct {
+	state atomic.Uint32
+
+	opts        Options
+	kickService *kick.Service
+	dash        *dashboard.Dashboard
+	logger      *logrus.Logger
+
+	// budget gates how many connections may be in flight at once when
+	// opts.ProxyBudget is set; nil means unlimited.
+	budget chan struct{}
+
+	// nextIndex is the next unused connection index, consumed both by the
+	// initial spawner and by Scale so indices never collide.
+	nextIndex atomic.Int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	pauseMu   sync.Mutex
+	pauseCond *sync.Cond
+}
+
+// New creates an Engine ready to Start. dash may be nil when NoDashboard is set.
+func New(kickService *kick.Service, logger *logrus.Logger, dash *dashboard.Dashboard, opts Options) *Engine {
+	e := &Engine{
+		opts:        opts,
+		kickService: kickService,
+		dash:        dash,
+		logger:      logger,
+	}
+	if opts.ProxyBudget > 0 {
+		e.budget = make(chan struct{}, opts.ProxyBudget)
+	}
+	e.nextIndex.Store(int64(opts.TotalViewers))
+	e.pauseCond = sync.NewCond(&e.pauseMu)
+	e.state.Store(uint32(StateNew))
+	return e
+}
+
+// State returns the engine's current lifecycle state.
+func (e *Engine) State() State {
+	return State(e.state.Load())
+}
+
+// Start transitions the engine from StateNew to StateRunning and spawns the
+// connection workers in the background. It is a no-op if already started.
+func (e *Engine) Start(ctx context.Context) {
+	if !e.state.CompareAndSwap(uint32(StateNew), uint32(StateRunning)) {
+		return
+	}
+
+	e.ctx, e.cancel = context.WithCancel(ctx)
+	go e.run()
+}
+
+// Pause halts new connection attempts. Connections already established keep
+// their websocket sessions alive and continue their message loops normally.
+func (e *Engine) Pause() {
+	if e.state.CompareAndSwap(uint32(StateRunning), uint32(StatePaused)) {
+		e.logger.Info("Engine paused: no new connections will be started")
+	}
+}
+
+// Resume restarts the connection spawner after a Pause.
+func (e *Engine) Resume() {
+	if e.state.CompareAndSwap(uint32(StatePaused), uint32(StateRunning)) {
+		e.pauseMu.Lock()
+		e.pauseCond.Broadcast()
+		e.pauseMu.Unlock()
+		e.logger.Info("Engine resumed")
+	}
+}
+
+// Stop cancels the run context, closes every live connection, and blocks
+// until all connection goroutines have exited.
+func (e *Engine) Stop() {
+	e.state.Store(uint32(StateStopped))
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	// Wake the spawner if it's currently blocked waiting to resume, so it
+	// can observe the stop instead of hanging forever.
+	e.pauseMu.Lock()
+	e.pauseCond.Broadcast()
+	e.pauseMu.Unlock()
+
+	e.wg.Wait()
+}
+
+// Wait blocks until every connection goroutine has finished, without
+// stopping the engine.
+func (e *Engine) Wait() {
+	e.wg.Wait()
+}
+
+// Dashboard returns the engine's dashboard, or nil when NoDashboard was set.
+// It exists so callers like the admin API can read live stats without
+// holding onto their own copy of the dashboard pointer.
+func (e *Engine) Dashboard() *dashboard.Dashboard {
+	return e.dash
+}
+
+// Options returns the Options the engine was constructed with.
+func (e *Engine) Options() Options {
+	return e.opts
+}
+
+// Scale spawns delta additional connection goroutines on top of whatever is
+// already running. Shrinking isn't supported: connections run as
+// independent goroutines with no per-connection cancellation, so there's no
+// way to tear down a specific one without stopping the whole engine; delta
+// must be positive.
+func (e *Engine) Scale(delta int) error {
+	if delta <= 0 {
+		return fmt.Errorf("engine: scale delta must be positive (got %d); shrinking a running engine isn't supported, use Stop instead", delta)
+	}
+	if e.State() == StateStopped {
+		return fmt.Errorf("engine: cannot scale a stopped engine")
+	}
+
+	start := e.nextIndex.Add(int64(delta)) - int64(delta)
+	if e.dash != nil {
+		e.dash.AddCapacity(delta)
+	}
+
+	for i := 0; i < delta; i++ {
+		index := int(start) + i
+		e.wg.Add(1)
+		go e.startConnection(index)
+	}
+
+	return nil
+}
+
+// ListenStdinControls spawns a goroutine reading single-key commands from
+// stdin: 'p' pauses, 'r' resumes, 'q' stops. It exits once ctx is done or
+// stdin is closed.
+func (e *Engine) ListenStdinControls(ctx context.Context) {
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			r, _, err := reader.ReadRune()
+			if err != nil {
+				return
+			}
+
+			switch r {
+			case 'p', 'P':
+				e.Pause()
+			case 'r', 'R':
+				e.Resume()
+			case 'q', 'Q':
+				e.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// waitIfPaused blocks while the engine is paused and reports whether the
+// caller should keep going (false means the engine was stopped).
+func (e *Engine) waitIfPaused() bool {
+	e.pauseMu.Lock()
+	for e.State() == StatePaused {
+		e.pauseCond.Wait()
+	}
+	e.pauseMu.Unlock()
+	return e.State() != StateStopped
+}
+
+// run drives the spawner loop in batch or simultaneous mode depending on
+// opts.SlowMode, then waits for every connection to finish.
+func (e *Engine) run() {
+	if e.opts.SlowMode {
+		e.runBatches()
+	} else {
+		e.runSimultaneous()
+	}
+}
+
+func (e *Engine) runSimultaneous() {
+	for i := 0; i < e.opts.TotalViewers; i++ {
+		if !e.waitIfPaused() {
+			return
+		}
+		select {
+		case <-e.ctx.Done():
+			return
+		default:
+		}
+
+		e.wg.Add(1)
+		go e.startConnection(i)
+	}
+}
+
+func (e *Engine) runBatches() {
+	for i := 0; i < e.opts.TotalViewers; i += e.opts.BatchSize {
+		if !e.waitIfPaused() {
+			return
+		}
+		select {
+		case <-e.ctx.Done():
+			return
+		default:
+		}
+
+		end := i + e.opts.BatchSize
+		if end > e.opts.TotalViewers {
+			end = e.opts.TotalViewers
+		}
+
+		for j := i; j < end; j++ {
+			if !e.waitIfPaused() {
+				return
+			}
+			e.wg.Add(1)
+			go e.startConnection(j)
+		}
+
+		if end < e.opts.TotalViewers {
+			timer := time.NewTimer(e.opts.BatchDelay)
+			select {
+			case <-e.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// startConnection handles a single fake-view connection end to end.
+func (e *Engine) startConnection(index int) {
+	defer e.wg.Done()
+
+	if e.budget != nil {
+		select {
+		case e.budget <- struct{}{}:
+			defer func() { <-e.budget }()
+		case <-e.ctx.Done():
+			return
+		}
+	}
+
+	ctx := xlog.NewContext(e.ctx, xlog.FromContextOrDefault(e.ctx, e.logger).WithConnID(index))
+
+	if !e.opts.NoDashboard && e.dash != nil {
+		e.dash.UpdateConnection(index, dashboard.StatusConnecting, 1, "")
+	}
+
+	token, p, err := e.kickService.GetToken(ctx)
+	if err != nil {
+		if e.opts.NoDashboard {
+			e.logger.WithError(err).Errorf("[%d] Failed to get token", index)
+		} else if e.dash != nil {
+			e.dash.UpdateConnection(index, dashboard.StatusFailed, 1, err.Error())
+		}
+		return
+	}
+	proxyURL := p.GetProxyURL()
+	ctx = xlog.NewContext(ctx, xlog.FromContextOrDefault(ctx, e.logger).WithProxy(p.Key()))
+
+	if e.opts.NoDashboard {
+		e.logger.Infof("[%d] Got token: %s using proxy: %s", index, token, proxyURL)
+	}
+
+	handler := kick.NewConnectionHandler(index, e.opts.ChannelID, token, proxyURL, e.logger)
+	handler.SetProxyFeedback(e.kickService.ProxyManager(), p)
+
+	var dash *dashboard.Dashboard
+	if !e.opts.NoDashboard {
+		dash = e.dash
+	}
+	connErr := handler.Start(ctx, dash)
+
+	if connErr == nil || connErr == context.Canceled {
+		if connErr == context.Canceled && e.opts.NoDashboard {
+			e.logger.Infof("[%d] Connection stopped due to shutdown", index)
+		}
+		return
+	}
+
+	if e.opts.NoDashboard {
+		e.logger.WithError(connErr).Errorf("[%d] Connection failed", index)
+	} else if e.dash != nil {
+		e.dash.UpdateConnection(index, dashboard.StatusFailed, 1, connErr.Error())
+	}
+}
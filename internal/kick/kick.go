@@ -1,6 +1,7 @@
 package kick
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,17 +9,19 @@ import (
 	"time"
 
 	"kick-bot/internal/client"
+	"kick-bot/internal/config"
 	"kick-bot/internal/proxy"
+	"kick-bot/internal/xlog"
 
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	maxRetries  = 5
-	baseURL     = "https://kick.com"
-	apiBaseURL  = "https://kick.com/api/v2"
-	wsTokenURL  = "https://websockets.kick.com/viewer/v1/token"
-	clientToken = "e1393935a959b4020a4491574f6490129f678acdaa92760471263db43487f823"
+	// apiHost and wsHost are the per-host keys used with the proxy
+	// manager's host skip-list, since a proxy can be blocked by Kick on
+	// one host while still working fine on the other.
+	apiHost = "kick.com"
+	wsHost  = "websockets.kick.com"
 )
 
 // ChannelResponse represents the API response for channel information
@@ -37,42 +40,79 @@ type TokenResponse struct {
 type Service struct {
 	proxyManager *proxy.ProxyManager
 	logger       *logrus.Logger
+	cfg          *config.Config
 }
 
-// NewService creates a new Kick service instance
-func NewService(proxyManager *proxy.ProxyManager, logger *logrus.Logger) *Service {
+// NewService creates a new Kick service instance. cfg may be nil, in which
+// case config.Default() is used.
+func NewService(proxyManager *proxy.ProxyManager, logger *logrus.Logger, cfg *config.Config) *Service {
+	if cfg == nil {
+		cfg = config.Default()
+	}
 	return &Service{
 		proxyManager: proxyManager,
 		logger:       logger,
+		cfg:          cfg,
 	}
 }
 
-// GetChannelID retrieves the channel ID for a given channel name
-func (s *Service) GetChannelID(channelName string) (int, error) {
-	url := fmt.Sprintf("%s/channels/%s", apiBaseURL, channelName)
+// proxyManagerProxyForHost returns a healthy proxy that hasn't been
+// blacklisted for host, retrying the underlying selection a few times to
+// skip over proxies the skip-list has ruled out for this target.
+func (s *Service) proxyManagerProxyForHost(ctx context.Context, host string) (proxy.Proxy, error) {
+	const maxSkipAttempts = 5
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		s.logger.Infof("Attempting to get channel ID for '%s', attempt %d/%d", channelName, attempt, maxRetries)
+	var lastErr error
+	for i := 0; i < maxSkipAttempts; i++ {
+		p, err := s.proxyManager.Next()
+		if err != nil {
+			return proxy.Proxy{}, err
+		}
 
-		// Get a random proxy
-		p, err := s.proxyManager.GetRandomProxy()
+		if !s.proxyManager.IsSkippedForHost(p, host) {
+			return p, nil
+		}
+		lastErr = fmt.Errorf("proxy %s is blacklisted for host %s", p.Key(), host)
+	}
+
+	return proxy.Proxy{}, lastErr
+}
+
+// GetChannelID retrieves the channel ID for a given channel name. Log lines
+// are tagged with the channel name and attempt number via the xlog.Logger
+// carried on ctx (see xlog.FromContext).
+func (s *Service) GetChannelID(ctx context.Context, channelName string) (int, error) {
+	url := fmt.Sprintf("%s/channels/%s", s.cfg.APIBaseURL, channelName)
+	base := xlog.FromContextOrDefault(ctx, s.logger).WithChannelName(channelName)
+
+	for attempt := 1; attempt <= s.cfg.MaxRetries; attempt++ {
+		xl := base.WithAttempt(attempt)
+		xl.Infof("Attempting to get channel ID for '%s', attempt %d/%d", channelName, attempt, s.cfg.MaxRetries)
+
+		// Get a random proxy that hasn't been blacklisted for this host
+		p, err := s.proxyManagerProxyForHost(ctx, apiHost)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to get proxy")
+			xl.WithError(err).Error("Failed to get proxy")
 			continue
 		}
+		xl = xl.WithProxy(p.Key())
 
 		// Create TLS client with proxy
-		c, err := client.NewTLSClient(p, s.logger)
+		c, err := client.NewTLSClient(p, s.cfg)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to create TLS client")
+			xl.WithError(err).Error("Failed to create TLS client")
 			continue
 		}
 
 		// Make request with a small delay to avoid rate limiting
-		time.Sleep(200 * time.Millisecond)
-		resp, err := c.Get(url, nil)
+		time.Sleep(s.cfg.RequestDelay.ChannelID)
+		reqStart := time.Now()
+		resp, err := c.Get(ctx, url, nil)
+		latency := time.Since(reqStart)
 		if err != nil {
-			s.logger.WithError(err).Errorf("Request failed for channel %s, retrying...", channelName)
+			xl.WithError(err).Errorf("Request failed for channel %s, retrying...", channelName)
+			s.proxyManager.MarkHostUnhealthy(p, apiHost)
+			s.proxyManager.ReportFailure(p, latency, err)
 			time.Sleep(1 * time.Second)
 			continue
 		}
@@ -81,7 +121,7 @@ func (s *Service) GetChannelID(channelName string) (int, error) {
 		if resp.StatusCode == 200 {
 			body, err := io.ReadAll(resp.Body)
 			if err != nil {
-				s.logger.WithError(err).Error("Failed to read response body")
+				xl.WithError(err).Error("Failed to read response body")
 				continue
 			}
 
@@ -91,68 +131,90 @@ func (s *Service) GetChannelID(channelName string) (int, error) {
 				if len(preview) > 100 {
 					preview = preview[:100] + "..."
 				}
-				s.logger.Debugf("Response preview: %s", preview)
+				xl.Debugf("Response preview: %s", preview)
 			}
 
 			var channelResp ChannelResponse
 			if err := json.Unmarshal(body, &channelResp); err != nil {
-				s.logger.WithError(err).Errorf("Failed to parse channel response. Body length: %d", len(body))
+				xl.WithError(err).Errorf("Failed to parse channel response. Body length: %d", len(body))
 				// Try to log the raw response if it's small enough
 				if len(body) < 500 {
-					s.logger.Debugf("Raw response: %s", string(body))
+					xl.Debugf("Raw response: %s", string(body))
 				}
 				continue
 			}
 
-			s.logger.Infof("Successfully retrieved channel ID: %d for channel '%s'", channelResp.ID, channelName)
+			xl.Infof("Successfully retrieved channel ID: %d for channel '%s'", channelResp.ID, channelName)
+			s.proxyManager.ReportSuccess(p, latency)
 			return channelResp.ID, nil
 		}
 
-		s.logger.Warnf("Received status code %d for channel %s, retrying...", resp.StatusCode, channelName)
+		xl.Warnf("Received status code %d for channel %s, retrying...", resp.StatusCode, channelName)
+		s.proxyManager.ReportFailure(p, latency, fmt.Errorf("unexpected status %d", resp.StatusCode))
 		time.Sleep(1 * time.Second)
 	}
 
-	s.logger.Errorf("Failed to get channel ID for '%s' after %d attempts", channelName, maxRetries)
-	return 0, fmt.Errorf("failed to get channel ID for '%s' after %d attempts", channelName, maxRetries)
+	base.Errorf("Failed to get channel ID for '%s' after %d attempts", channelName, s.cfg.MaxRetries)
+	return 0, fmt.Errorf("failed to get channel ID for '%s' after %d attempts", channelName, s.cfg.MaxRetries)
 }
 
-// GetToken retrieves a websocket token and returns it along with the proxy URL used
-func (s *Service) GetToken() (string, string, error) {
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		s.logger.Infof("Attempting to get websocket token, attempt %d/%d", attempt, maxRetries)
+// ProxyManager returns the service's underlying proxy manager, so callers
+// that hold a proxy.Proxy returned from GetToken can report connection
+// outcomes back into the rebalancer themselves.
+func (s *Service) ProxyManager() *proxy.ProxyManager {
+	return s.proxyManager
+}
+
+// GetToken retrieves a websocket token and returns it along with the proxy
+// used. Log lines are tagged with the attempt number and proxy via the
+// xlog.Logger carried on ctx (see xlog.FromContext).
+func (s *Service) GetToken(ctx context.Context) (string, proxy.Proxy, error) {
+	base := xlog.FromContextOrDefault(ctx, s.logger)
+
+	for attempt := 1; attempt <= s.cfg.MaxRetries; attempt++ {
+		xl := base.WithAttempt(attempt)
+		xl.Infof("Attempting to get websocket token, attempt %d/%d", attempt, s.cfg.MaxRetries)
 
-		// Get a random proxy
-		p, err := s.proxyManager.GetRandomProxy()
+		// Get a random proxy that hasn't been blacklisted for this host
+		p, err := s.proxyManagerProxyForHost(ctx, wsHost)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to get proxy")
+			xl.WithError(err).Error("Failed to get proxy")
 			continue
 		}
+		xl = xl.WithProxy(p.Key())
 
 		// Create TLS client with proxy
-		c, err := client.NewTLSClient(p, s.logger)
+		c, err := client.NewTLSClient(p, s.cfg)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to create TLS client")
+			xl.WithError(err).Error("Failed to create TLS client")
 			continue
 		}
 
 		// First, visit the main page to establish session with delay
-		time.Sleep(300 * time.Millisecond)
-		_, err = c.Get(baseURL, nil)
+		time.Sleep(s.cfg.RequestDelay.Token)
+		pageStart := time.Now()
+		_, err = c.Get(ctx, s.cfg.BaseURL, nil)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to visit main page")
+			xl.WithError(err).Error("Failed to visit main page")
+			s.proxyManager.MarkHostUnhealthy(p, wsHost)
+			s.proxyManager.ReportFailure(p, time.Since(pageStart), err)
 			continue
 		}
 
 		// Prepare headers for token request
 		headers := map[string]string{
-			"X-CLIENT-TOKEN": clientToken,
+			"X-CLIENT-TOKEN": s.cfg.ClientToken,
 		}
 
 		// Make token request with delay
-		time.Sleep(200 * time.Millisecond)
-		resp, err := c.Get(wsTokenURL, headers)
+		time.Sleep(s.cfg.RequestDelay.Token)
+		tokenStart := time.Now()
+		resp, err := c.Get(ctx, s.cfg.WSTokenURL, headers)
+		latency := time.Since(tokenStart)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to get token, trying another proxy...")
+			xl.WithError(err).Error("Failed to get token, trying another proxy...")
+			s.proxyManager.MarkHostUnhealthy(p, wsHost)
+			s.proxyManager.ReportFailure(p, latency, err)
 			time.Sleep(1 * time.Second)
 			continue
 		}
@@ -161,27 +223,28 @@ func (s *Service) GetToken() (string, string, error) {
 		if resp.StatusCode == 200 {
 			body, err := io.ReadAll(resp.Body)
 			if err != nil {
-				s.logger.WithError(err).Error("Failed to read token response body")
+				xl.WithError(err).Error("Failed to read token response body")
 				continue
 			}
 
 			var tokenResp TokenResponse
 			if err := json.Unmarshal(body, &tokenResp); err != nil {
-				s.logger.WithError(err).Error("Failed to parse token response")
+				xl.WithError(err).Error("Failed to parse token response")
 				continue
 			}
 
-			proxyURL := c.GetProxyURL()
-			s.logger.Infof("Successfully retrieved websocket token using proxy %s", c.GetProxyInfo())
-			return tokenResp.Data.Token, proxyURL, nil
+			xl.Infof("Successfully retrieved websocket token using proxy %s", c.GetProxyInfo())
+			s.proxyManager.ReportSuccess(p, latency)
+			return tokenResp.Data.Token, p, nil
 		}
 
-		s.logger.Warnf("Received status code %d for token request, trying another proxy...", resp.StatusCode)
+		xl.Warnf("Received status code %d for token request, trying another proxy...", resp.StatusCode)
+		s.proxyManager.ReportFailure(p, latency, fmt.Errorf("unexpected status %d", resp.StatusCode))
 		time.Sleep(1 * time.Second)
 	}
 
-	s.logger.Errorf("Failed to get websocket token after %d attempts", maxRetries)
-	return "", "", fmt.Errorf("failed to get websocket token after %d attempts", maxRetries)
+	base.Errorf("Failed to get websocket token after %d attempts", s.cfg.MaxRetries)
+	return "", proxy.Proxy{}, fmt.Errorf("failed to get websocket token after %d attempts", s.cfg.MaxRetries)
 }
 
 // ExtractChannelName extracts channel name from a Kick URL or returns the input if it's already a channel name
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"kick-bot/internal/dashboard"
+	"kick-bot/internal/proxy"
+	"kick-bot/internal/xlog"
 
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
@@ -35,6 +37,13 @@ type ConnectionHandler struct {
 	proxyURL  string
 	logger    *logrus.Logger
 	conn      *websocket.Conn
+
+	// proxyManager/proxyObj are optional: when both are set, connect() and
+	// messageLoop report their outcomes back into the rebalancer so it
+	// learns from live websocket traffic, not just the HTTP requests made
+	// while fetching the token.
+	proxyManager *proxy.ProxyManager
+	proxyObj     proxy.Proxy
 }
 
 // NewConnectionHandler creates a new websocket connection handler
@@ -48,49 +57,56 @@ func NewConnectionHandler(index, channelID int, token, proxyURL string, logger *
 	}
 }
 
-// Start begins the websocket connection and message loop
-func (ch *ConnectionHandler) Start(ctx context.Context) error {
-	maxRetries := 5
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		ch.logger.Infof("[%d] Starting connection attempt %d/%d", ch.index, attempt, maxRetries)
-
-		if err := ch.connect(); err != nil {
-			ch.logger.WithError(err).Errorf("[%d] Connection attempt %d failed", ch.index, attempt)
-
-			// Wait before retrying
-			retryDelay := time.Duration(4+rand.Intn(5)) * time.Second
-			ch.logger.Infof("[%d] Retrying in %v...", ch.index, retryDelay)
-
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(retryDelay):
-				continue
-			}
-		}
+// SetProxyFeedback wires pm and p into the handler so connection and message
+// failures/successes are reported back into the rebalancer.
+func (ch *ConnectionHandler) SetProxyFeedback(pm *proxy.ProxyManager, p proxy.Proxy) {
+	ch.proxyManager = pm
+	ch.proxyObj = p
+}
 
-		// If connection successful, start message loop
-		return ch.messageLoop(ctx)
+// reportOutcome feeds a connect/write result back into the rebalancer, if
+// proxy feedback was configured via SetProxyFeedback. latency is the dial
+// duration for connect() outcomes, or 0 for message-loop write failures
+// where there's no comparable measurement.
+func (ch *ConnectionHandler) reportOutcome(success bool, latency time.Duration, err error) {
+	if ch.proxyManager == nil {
+		return
 	}
-
-	return fmt.Errorf("failed to establish connection after %d attempts", maxRetries)
+	if success {
+		ch.proxyManager.ReportSuccess(ch.proxyObj, latency)
+		return
+	}
+	ch.proxyManager.ReportFailure(ch.proxyObj, latency, err)
 }
 
-// StartWithDashboard begins the websocket connection with dashboard updates
-func (ch *ConnectionHandler) StartWithDashboard(ctx context.Context, dash *dashboard.Dashboard) error {
+// Start begins the websocket connection and message loop. When dash is
+// non-nil, its connection status is kept up to date throughout; when dash is
+// nil, progress is logged instead via the xlog.Logger carried on ctx (see
+// xlog.FromContext), tagged with this connection's index.
+func (ch *ConnectionHandler) Start(ctx context.Context, dash *dashboard.Dashboard) error {
 	maxRetries := 5
+	xl := xlog.FromContextOrDefault(ctx, ch.logger).WithConnID(ch.index)
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		// Update dashboard with current attempt
-		dash.UpdateConnection(ch.index, dashboard.StatusConnecting, attempt, "")
+		axl := xl.WithAttempt(attempt)
+		if dash != nil {
+			dash.UpdateConnection(ch.index, dashboard.StatusConnecting, attempt, "")
+		} else {
+			axl.Infof("[%d] Starting connection attempt %d/%d", ch.index, attempt, maxRetries)
+		}
 
-		if err := ch.connect(); err != nil {
-			// Update dashboard with retry status
-			dash.UpdateConnection(ch.index, dashboard.StatusRetrying, attempt, err.Error())
+		if err := ch.connect(ctx); err != nil {
+			if dash != nil {
+				dash.UpdateConnection(ch.index, dashboard.StatusRetrying, attempt, err.Error())
+			} else {
+				axl.WithError(err).Errorf("[%d] Connection attempt %d failed", ch.index, attempt)
+			}
 
 			// Wait before retrying
 			retryDelay := time.Duration(4+rand.Intn(5)) * time.Second
+			if dash == nil {
+				axl.Infof("[%d] Retrying in %v...", ch.index, retryDelay)
+			}
 
 			select {
 			case <-ctx.Done():
@@ -100,18 +116,21 @@ func (ch *ConnectionHandler) StartWithDashboard(ctx context.Context, dash *dashb
 			}
 		}
 
-		// Connection successful, update dashboard
-		dash.UpdateConnection(ch.index, dashboard.StatusConnected, attempt, "")
+		if dash != nil {
+			dash.UpdateConnection(ch.index, dashboard.StatusConnected, attempt, "")
+		}
 
-		// Start message loop
-		return ch.messageLoopWithDashboard(ctx, dash)
+		// If connection successful, start message loop
+		return ch.messageLoop(ctx, dash)
 	}
 
 	return fmt.Errorf("failed to establish connection after %d attempts", maxRetries)
 }
 
 // connect establishes the websocket connection
-func (ch *ConnectionHandler) connect() error {
+func (ch *ConnectionHandler) connect(ctx context.Context) error {
+	xl := xlog.FromContextOrDefault(ctx, ch.logger).WithConnID(ch.index)
+
 	// Create websocket URL
 	wsURL := fmt.Sprintf("wss://websockets.kick.com/viewer/v1/connect?token=%s", ch.token)
 
@@ -135,85 +154,34 @@ func (ch *ConnectionHandler) connect() error {
 	}
 
 	// Establish websocket connection
-	ch.logger.Debugf("[%d] Connecting to %s via proxy %s", ch.index, wsURL, ch.proxyURL)
+	xl.Debugf("[%d] Connecting to %s via proxy %s", ch.index, wsURL, ch.proxyURL)
 
+	dialStart := time.Now()
 	conn, _, err := dialer.Dial(wsURL, nil)
+	latency := time.Since(dialStart)
 	if err != nil {
+		ch.reportOutcome(false, latency, err)
 		return fmt.Errorf("websocket dial failed: %w", err)
 	}
 
 	ch.conn = conn
-	ch.logger.Infof("[%d] WebSocket connection established", ch.index)
+	ch.reportOutcome(true, latency, nil)
+	xl.Infof("[%d] WebSocket connection established", ch.index)
 	return nil
 }
 
-// messageLoop handles the ping/handshake message cycle
-func (ch *ConnectionHandler) messageLoop(ctx context.Context) error {
-	defer func() {
-		if ch.conn != nil {
-			ch.conn.Close()
-			ch.logger.Infof("[%d] WebSocket connection closed", ch.index)
-		}
-	}()
-
-	counter := 0
-
-	for {
-		select {
-		case <-ctx.Done():
-			ch.logger.Infof("[%d] Context cancelled, stopping message loop", ch.index)
-			return ctx.Err()
-		default:
-		}
-
-		counter++
+// messageLoop handles the ping/handshake message cycle. When dash is
+// non-nil, its connection status is kept up to date; when dash is nil,
+// progress is logged via ctx's xlog.Logger instead.
+func (ch *ConnectionHandler) messageLoop(ctx context.Context, dash *dashboard.Dashboard) error {
+	xl := xlog.FromContextOrDefault(ctx, ch.logger).WithConnID(ch.index)
 
-		var message WebSocketMessage
-
-		if counter%2 == 0 {
-			// Send ping message
-			message = WebSocketMessage{
-				Type: "ping",
-			}
-			ch.logger.Debugf("[%d] Sending ping", ch.index)
-		} else {
-			// Send handshake message
-			handshakeData := HandshakeData{}
-			handshakeData.Message.ChannelID = ch.channelID
-
-			message = WebSocketMessage{
-				Type: "channel_handshake",
-				Data: handshakeData,
-			}
-			ch.logger.Debugf("[%d] Sending handshake for channel %d", ch.index, ch.channelID)
-		}
-
-		// Send message
-		if err := ch.conn.WriteJSON(message); err != nil {
-			ch.logger.WithError(err).Errorf("[%d] Failed to send message", ch.index)
-			return fmt.Errorf("failed to send message: %w", err)
-		}
-
-		// Calculate random delay (11-18 seconds)
-		delay := time.Duration(11+rand.Intn(8)) * time.Second
-		ch.logger.Debugf("[%d] Waiting %v before next message", ch.index, delay)
-
-		// Wait for the delay or context cancellation
-		select {
-		case <-ctx.Done():
-			ch.logger.Infof("[%d] Context cancelled during delay", ch.index)
-			return ctx.Err()
-		case <-time.After(delay):
-			// Continue to next iteration
-		}
-	}
-}
-
-// messageLoopWithDashboard handles the ping/handshake message cycle with dashboard updates
-func (ch *ConnectionHandler) messageLoopWithDashboard(ctx context.Context, dash *dashboard.Dashboard) error {
 	defer func() {
 		if ch.conn != nil {
 			ch.conn.Close()
+			if dash == nil {
+				xl.Infof("[%d] WebSocket connection closed", ch.index)
+			}
 		}
 	}()
 
@@ -222,6 +190,9 @@ func (ch *ConnectionHandler) messageLoopWithDashboard(ctx context.Context, dash
 	for {
 		select {
 		case <-ctx.Done():
+			if dash == nil {
+				xl.Infof("[%d] Context cancelled, stopping message loop", ch.index)
+			}
 			return ctx.Err()
 		default:
 		}
@@ -235,6 +206,9 @@ func (ch *ConnectionHandler) messageLoopWithDashboard(ctx context.Context, dash
 			message = WebSocketMessage{
 				Type: "ping",
 			}
+			if dash == nil {
+				xl.Debugf("[%d] Sending ping", ch.index)
+			}
 		} else {
 			// Send handshake message
 			handshakeData := HandshakeData{}
@@ -244,21 +218,34 @@ func (ch *ConnectionHandler) messageLoopWithDashboard(ctx context.Context, dash
 				Type: "channel_handshake",
 				Data: handshakeData,
 			}
+			if dash == nil {
+				xl.Debugf("[%d] Sending handshake for channel %d", ch.index, ch.channelID)
+			}
 		}
 
 		// Send message
 		if err := ch.conn.WriteJSON(message); err != nil {
-			// Update dashboard with error
-			dash.UpdateConnection(ch.index, dashboard.StatusFailed, 1, "Message send failed: "+err.Error())
+			if dash != nil {
+				dash.UpdateConnection(ch.index, dashboard.StatusFailed, 1, "Message send failed: "+err.Error())
+			} else {
+				xl.WithError(err).Errorf("[%d] Failed to send message", ch.index)
+			}
+			ch.reportOutcome(false, 0, err)
 			return fmt.Errorf("failed to send message: %w", err)
 		}
 
 		// Calculate random delay (11-18 seconds)
 		delay := time.Duration(11+rand.Intn(8)) * time.Second
+		if dash == nil {
+			xl.Debugf("[%d] Waiting %v before next message", ch.index, delay)
+		}
 
 		// Wait for the delay or context cancellation
 		select {
 		case <-ctx.Done():
+			if dash == nil {
+				xl.Infof("[%d] Context cancelled during delay", ch.index)
+			}
 			return ctx.Err()
 		case <-time.After(delay):
 			// Continue to next iteration
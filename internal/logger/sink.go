@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// SinkMode selects where a Logger built by NewSink writes to.
+type SinkMode int
+
+const (
+	// SinkConsole writes only to stdout, same as NewTextLogger.
+	SinkConsole SinkMode = iota
+	// SinkFilesystem writes only to a rotated log file.
+	SinkFilesystem
+	// SinkMulti writes to both, each at its own level, so the console (or
+	// dashboard) can stay quiet while the file captures full debug traces.
+	SinkMulti
+)
+
+// SinkConfig configures NewSink. ConsoleLevel and FileLevel are independent:
+// in SinkMulti mode the console can run at logrus.WarnLevel while the file
+// still gets logrus.DebugLevel.
+type SinkConfig struct {
+	Mode SinkMode
+
+	ConsoleLevel logrus.Level
+	FileLevel    logrus.Level
+
+	// LogFile is the path NewSink rotates into (ignored in SinkConsole
+	// mode). MaxSizeMB, MaxBackups, and MaxAgeDays follow lumberjack's
+	// field names and defaults (100MB, 0 = keep all backups, 0 = never
+	// expire by age) when left at zero.
+	LogFile    string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// NewSink builds a *logrus.Logger per cfg.Mode: SinkConsole behaves like
+// NewTextLogger, SinkFilesystem writes only to the rotated file described by
+// cfg, and SinkMulti writes to both at their own independent levels via
+// logrus hooks, since logrus.Logger itself only has one Out and one Level.
+func NewSink(cfg SinkConfig) *logrus.Logger {
+	log := logrus.New()
+	formatter := &logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02 15:04:05",
+	}
+
+	switch cfg.Mode {
+	case SinkFilesystem:
+		log.SetOutput(io.Discard)
+		log.SetLevel(cfg.FileLevel)
+		log.AddHook(newLevelWriterHook(cfg.fileWriter(), cfg.FileLevel, formatter))
+
+	case SinkMulti:
+		log.SetOutput(io.Discard)
+		log.SetLevel(maxLevel(cfg.ConsoleLevel, cfg.FileLevel))
+		consoleFormatter := &logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+			ForceColors:     true,
+		}
+		log.AddHook(newLevelWriterHook(os.Stdout, cfg.ConsoleLevel, consoleFormatter))
+		log.AddHook(newLevelWriterHook(cfg.fileWriter(), cfg.FileLevel, formatter))
+
+	default: // SinkConsole
+		log.SetOutput(os.Stdout)
+		log.SetLevel(cfg.ConsoleLevel)
+		log.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+			ForceColors:     true,
+		})
+	}
+
+	return log
+}
+
+// fileWriter builds the lumberjack-rotated writer for cfg.LogFile.
+func (cfg SinkConfig) fileWriter() io.Writer {
+	return &lumberjack.Logger{
+		Filename:   cfg.LogFile,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+	}
+}
+
+// maxLevel returns the more verbose (numerically larger) of two logrus
+// levels, so the base Logger's own level never filters out an entry one of
+// the hooks still wants to see.
+func maxLevel(a, b logrus.Level) logrus.Level {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// levelWriterHook writes formatted entries to writer, but only those at or
+// above its own level — independent of the base Logger's level, which (in
+// multi mode) is set to the most verbose of all the hooks so nothing is
+// dropped before it reaches them.
+type levelWriterHook struct {
+	writer    io.Writer
+	level     logrus.Level
+	formatter logrus.Formatter
+}
+
+func newLevelWriterHook(writer io.Writer, level logrus.Level, formatter logrus.Formatter) *levelWriterHook {
+	return &levelWriterHook{writer: writer, level: level, formatter: formatter}
+}
+
+// Levels reports that this hook wants every entry; filtering on h.level
+// happens in Fire so it can differ from the base Logger's own level.
+func (h *levelWriterHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *levelWriterHook) Fire(entry *logrus.Entry) error {
+	if entry.Level > h.level {
+		return nil
+	}
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(line)
+	return err
+}
@@ -0,0 +1,275 @@
+package proxy
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState is one of the states a per-proxy CircuitBreaker moves
+// through, modeled on vulcand/oxy's cbreaker: Standby serves traffic
+// normally, Tripped refuses it outright, and Recovering serves a limited
+// probe volume to decide whether to go back to Standby or re-trip.
+type CircuitBreakerState int
+
+const (
+	Standby CircuitBreakerState = iota
+	Tripped
+	Recovering
+)
+
+// String implements fmt.Stringer so CircuitBreakerState can be logged or
+// rendered directly.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case Standby:
+		return "standby"
+	case Tripped:
+		return "tripped"
+	case Recovering:
+		return "recovering"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// circuitWindowSize is how many recent outcomes the rolling window
+	// keeps for NetworkErrorRatio/LatencyAtQuantile.
+	circuitWindowSize = 50
+
+	defaultCircuitCooldown    = 30 * time.Second
+	defaultCircuitErrorRatio  = 0.5
+	defaultCircuitLatencyTrip = 20 * time.Second
+	defaultCircuitHalfOpenCap = 3
+)
+
+// circuitOutcome is one recorded call result in a CircuitBreaker's rolling
+// window.
+type circuitOutcome struct {
+	success bool
+	latency time.Duration
+}
+
+// CircuitBreaker tracks a rolling window of outcomes for a single proxy and
+// trips it out of rotation once the trip condition —
+// NetworkErrorRatio() > 0.5 || LatencyAtQuantile(50.0) > 20s — is met.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	state     CircuitBreakerState
+	trippedAt time.Time
+	cooldown  time.Duration
+
+	window   []circuitOutcome
+	writeIdx int
+
+	halfOpenCap      int
+	halfOpenInFlight int
+	halfOpenFailed   bool
+}
+
+// newCircuitBreaker creates a CircuitBreaker in the Standby state with the
+// package defaults for cooldown and half-open probe volume.
+func newCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		state:       Standby,
+		cooldown:    defaultCircuitCooldown,
+		halfOpenCap: defaultCircuitHalfOpenCap,
+	}
+}
+
+// State returns the breaker's current state, first applying the
+// Tripped -> Recovering transition if the cooldown has elapsed.
+func (cb *CircuitBreaker) State() CircuitBreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.maybeRecoverLocked()
+	return cb.state
+}
+
+// Allow reports whether a call through this proxy should be permitted right
+// now, and — for a Recovering breaker — counts it against the half-open
+// probe cap. Standby always allows; Tripped never does until its cooldown
+// elapses.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.maybeRecoverLocked()
+
+	switch cb.state {
+	case Tripped:
+		return false
+	case Recovering:
+		if cb.halfOpenInFlight >= cb.halfOpenCap {
+			return false
+		}
+		cb.halfOpenInFlight++
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *CircuitBreaker) maybeRecoverLocked() {
+	if cb.state == Tripped && time.Since(cb.trippedAt) >= cb.cooldown {
+		cb.state = Recovering
+		cb.halfOpenInFlight = 0
+		cb.halfOpenFailed = false
+	}
+}
+
+// RecordOutcome feeds a call result into the rolling window and evaluates
+// the breaker's trip/recovery condition: a Recovering breaker closes back
+// to Standby once halfOpenCap probes have succeeded, or re-trips on the
+// first failure; a Standby breaker trips the moment
+// NetworkErrorRatio() > 0.5 || LatencyAtQuantile(50.0) > 20s.
+func (cb *CircuitBreaker) RecordOutcome(success bool, latency time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.pushLocked(success, latency)
+
+	switch cb.state {
+	case Recovering:
+		if !success {
+			cb.halfOpenFailed = true
+			cb.tripLocked()
+			return
+		}
+		if cb.halfOpenInFlight >= cb.halfOpenCap && !cb.halfOpenFailed {
+			cb.state = Standby
+			cb.resetWindowLocked()
+		}
+	case Tripped:
+		// Outcomes can still arrive for a Tripped breaker (e.g. a caller
+		// that grabbed the proxy just before it tripped); they don't
+		// change the state, only the window used once it recovers.
+	default:
+		if cb.networkErrorRatioLocked() > defaultCircuitErrorRatio || cb.latencyAtQuantileLocked(50.0) > defaultCircuitLatencyTrip {
+			cb.tripLocked()
+		}
+	}
+}
+
+func (cb *CircuitBreaker) tripLocked() {
+	cb.state = Tripped
+	cb.trippedAt = time.Now()
+	cb.halfOpenInFlight = 0
+	cb.halfOpenFailed = false
+}
+
+func (cb *CircuitBreaker) pushLocked(success bool, latency time.Duration) {
+	o := circuitOutcome{success: success, latency: latency}
+	if len(cb.window) < circuitWindowSize {
+		cb.window = append(cb.window, o)
+		return
+	}
+	cb.window[cb.writeIdx] = o
+	cb.writeIdx = (cb.writeIdx + 1) % circuitWindowSize
+}
+
+func (cb *CircuitBreaker) resetWindowLocked() {
+	cb.window = cb.window[:0]
+	cb.writeIdx = 0
+}
+
+// NetworkErrorRatio returns the fraction of calls in the rolling window that
+// failed, for use in a trip condition like NetworkErrorRatio() > 0.5.
+func (cb *CircuitBreaker) NetworkErrorRatio() float64 {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.networkErrorRatioLocked()
+}
+
+func (cb *CircuitBreaker) networkErrorRatioLocked() float64 {
+	if len(cb.window) == 0 {
+		return 0
+	}
+	fails := 0
+	for _, o := range cb.window {
+		if !o.success {
+			fails++
+		}
+	}
+	return float64(fails) / float64(len(cb.window))
+}
+
+// LatencyAtQuantile returns the latency at percentile q (0-100) over the
+// rolling window, e.g. LatencyAtQuantile(50.0) for the median. Returns 0 if
+// the window is empty.
+func (cb *CircuitBreaker) LatencyAtQuantile(q float64) time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.latencyAtQuantileLocked(q)
+}
+
+func (cb *CircuitBreaker) latencyAtQuantileLocked(q float64) time.Duration {
+	if len(cb.window) == 0 {
+		return 0
+	}
+
+	latencies := make([]time.Duration, len(cb.window))
+	for i, o := range cb.window {
+		latencies[i] = o.latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	idx := int(q / 100 * float64(len(latencies)-1))
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(latencies) {
+		idx = len(latencies) - 1
+	}
+	return latencies[idx]
+}
+
+// breakerFor returns the CircuitBreaker for p, creating one in the Standby
+// state if this is the first time p has been seen.
+func (pm *ProxyManager) breakerFor(p Proxy) *CircuitBreaker {
+	key := p.Key()
+
+	pm.breakersMu.RLock()
+	cb, ok := pm.breakers[key]
+	pm.breakersMu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	pm.breakersMu.Lock()
+	defer pm.breakersMu.Unlock()
+	if cb, ok := pm.breakers[key]; ok {
+		return cb
+	}
+	cb = newCircuitBreaker()
+	pm.breakers[key] = cb
+	return cb
+}
+
+// CircuitState returns p's current circuit breaker state, for the dashboard
+// and admin API.
+func (pm *ProxyManager) CircuitState(p Proxy) CircuitBreakerState {
+	return pm.breakerFor(p).State()
+}
+
+// CircuitSummary tallies every known proxy's circuit breaker state, for the
+// dashboard to render next to the connection-status counters.
+func (pm *ProxyManager) CircuitSummary() (standby, tripped, recovering int) {
+	pm.mu.RLock()
+	proxies := make([]Proxy, len(pm.proxies))
+	copy(proxies, pm.proxies)
+	pm.mu.RUnlock()
+
+	for _, p := range proxies {
+		switch pm.CircuitState(p) {
+		case Standby:
+			standby++
+		case Tripped:
+			tripped++
+		case Recovering:
+			recovering++
+		}
+	}
+	return standby, tripped, recovering
+}
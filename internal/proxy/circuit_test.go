@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsOnErrorRatio(t *testing.T) {
+	cb := newCircuitBreaker()
+
+	for i := 0; i < 10; i++ {
+		cb.RecordOutcome(false, 0)
+	}
+
+	if got := cb.State(); got != Tripped {
+		t.Fatalf("expected breaker to trip after a string of failures, got %v", got)
+	}
+	if cb.Allow() {
+		t.Fatalf("expected a Tripped breaker to refuse calls")
+	}
+}
+
+func TestCircuitBreakerTripsOnLatency(t *testing.T) {
+	cb := newCircuitBreaker()
+
+	for i := 0; i < 10; i++ {
+		cb.RecordOutcome(true, 25*time.Second)
+	}
+
+	if got := cb.State(); got != Tripped {
+		t.Fatalf("expected breaker to trip on high median latency even with all successes, got %v", got)
+	}
+}
+
+func TestCircuitBreakerRecoversAfterCooldown(t *testing.T) {
+	cb := newCircuitBreaker()
+	cb.cooldown = 10 * time.Millisecond
+	cb.halfOpenCap = 2
+
+	for i := 0; i < 10; i++ {
+		cb.RecordOutcome(false, 0)
+	}
+	if got := cb.State(); got != Tripped {
+		t.Fatalf("expected breaker to trip, got %v", got)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+
+	if got := cb.State(); got != Recovering {
+		t.Fatalf("expected breaker to move to Recovering once cooldown elapsed, got %v", got)
+	}
+
+	// Serve halfOpenCap successful probes to close back to Standby.
+	if !cb.Allow() {
+		t.Fatalf("expected first recovery probe to be allowed")
+	}
+	cb.RecordOutcome(true, 0)
+	if !cb.Allow() {
+		t.Fatalf("expected second recovery probe to be allowed")
+	}
+	cb.RecordOutcome(true, 0)
+
+	if got := cb.State(); got != Standby {
+		t.Fatalf("expected breaker to close back to Standby after successful probes, got %v", got)
+	}
+}
+
+func TestCircuitBreakerRetripsOnFailedProbe(t *testing.T) {
+	cb := newCircuitBreaker()
+	cb.cooldown = 10 * time.Millisecond
+
+	for i := 0; i < 10; i++ {
+		cb.RecordOutcome(false, 0)
+	}
+	time.Sleep(15 * time.Millisecond)
+
+	if got := cb.State(); got != Recovering {
+		t.Fatalf("expected breaker to be Recovering, got %v", got)
+	}
+
+	if !cb.Allow() {
+		t.Fatalf("expected a recovery probe to be allowed")
+	}
+	cb.RecordOutcome(false, 0)
+
+	if got := cb.State(); got != Tripped {
+		t.Fatalf("expected a failed recovery probe to re-trip the breaker, got %v", got)
+	}
+}
+
+func TestCircuitBreakerRecoveringCapsProbeVolume(t *testing.T) {
+	cb := newCircuitBreaker()
+	cb.cooldown = 10 * time.Millisecond
+	cb.halfOpenCap = 1
+
+	for i := 0; i < 10; i++ {
+		cb.RecordOutcome(false, 0)
+	}
+	time.Sleep(15 * time.Millisecond)
+	cb.State() // force the Tripped -> Recovering transition
+
+	if !cb.Allow() {
+		t.Fatalf("expected the first probe to be allowed")
+	}
+	if cb.Allow() {
+		t.Fatalf("expected a second concurrent probe to be refused once halfOpenCap is exhausted")
+	}
+}
+
+func TestProxyManagerNextSkipsTrippedProxy(t *testing.T) {
+	pm := newTestManager(t)
+
+	bad := Proxy{IP: "10.0.1.1", Port: "1111"}
+	good := Proxy{IP: "10.0.1.2", Port: "2222"}
+	pm.proxies = append(pm.proxies, bad, good)
+
+	for i := 0; i < 10; i++ {
+		pm.ReportFailure(bad, 0, nil)
+	}
+	if got := pm.CircuitState(bad); got != Tripped {
+		t.Fatalf("expected bad proxy's breaker to trip, got %v", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		p, err := pm.Next()
+		if err != nil {
+			t.Fatalf("Next() returned error: %v", err)
+		}
+		if p.Key() == bad.Key() {
+			t.Fatalf("Next() returned a proxy with a Tripped circuit breaker")
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrNoHealthyProxies is returned when every loaded proxy is currently
+// unhealthy and there is nothing left to hand out.
+var ErrNoHealthyProxies = errors.New("no healthy proxies available")
+
+// Health represents the health state of a single proxy.
+type Health int
+
+const (
+	// HealthUnknown is the state a proxy starts in before its first check.
+	HealthUnknown Health = iota
+	HealthChecking
+	HealthHealthy
+	HealthUnhealthy
+)
+
+const (
+	defaultIPCheckerURL        = "https://api.ipify.org"
+	defaultProxyConnectTimeout = 10 * time.Second
+	defaultHealthCheckInterval = 60 * time.Second
+	defaultHealthCheckWorkers  = 50
+)
+
+// ProxyState tracks the health of a single proxy over time.
+type ProxyState struct {
+	mu sync.Mutex
+
+	Status           Health
+	LastCheck        time.Time
+	LastLatency      time.Duration
+	ExitIP           string
+	ConsecutiveFails int
+}
+
+func (s *ProxyState) snapshot() ProxyState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ProxyState{
+		Status:           s.Status,
+		LastCheck:        s.LastCheck,
+		LastLatency:      s.LastLatency,
+		ExitIP:           s.ExitIP,
+		ConsecutiveFails: s.ConsecutiveFails,
+	}
+}
+
+func (s *ProxyState) recordSuccess(latency time.Duration, exitIP string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = HealthHealthy
+	s.LastCheck = time.Now()
+	s.LastLatency = latency
+	s.ExitIP = exitIP
+	s.ConsecutiveFails = 0
+}
+
+func (s *ProxyState) recordFailure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = HealthUnhealthy
+	s.LastCheck = time.Now()
+	s.ConsecutiveFails++
+}
+
+func (s *ProxyState) markChecking() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Status = HealthChecking
+}
+
+// ProxyStats is a snapshot of a proxy's health, keyed by the proxy it
+// describes. It's returned by Stats() for the dashboard to render.
+type ProxyStats struct {
+	Proxy            Proxy
+	Status           Health
+	LastCheck        time.Time
+	LastLatency      time.Duration
+	ExitIP           string
+	ConsecutiveFails int
+}
+
+// StartHealthChecker validates every loaded proxy once immediately and then
+// again on every healthCheckInterval tick, using a bounded worker pool. It
+// runs until ctx is cancelled.
+func (pm *ProxyManager) StartHealthChecker(ctx context.Context) {
+	pm.checkAllProxies(ctx)
+
+	go func() {
+		ticker := time.NewTicker(pm.healthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pm.checkAllProxies(ctx)
+			}
+		}
+	}()
+}
+
+// checkAllProxies dials every known proxy through a bounded worker pool.
+func (pm *ProxyManager) checkAllProxies(ctx context.Context) {
+	pm.mu.RLock()
+	proxies := make([]Proxy, len(pm.proxies))
+	copy(proxies, pm.proxies)
+	pm.mu.RUnlock()
+
+	sem := make(chan struct{}, pm.healthCheckWorkers)
+	var wg sync.WaitGroup
+
+	for _, p := range proxies {
+		p := p
+		select {
+		case <-ctx.Done():
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pm.checkProxy(ctx, p)
+		}()
+	}
+
+	wg.Wait()
+	pm.logger.Infof("Health check pass complete: %d/%d proxies healthy", pm.healthyCount(), len(proxies))
+}
+
+// checkProxy dials a single proxy against ipCheckerURL and records the result.
+func (pm *ProxyManager) checkProxy(ctx context.Context, p Proxy) {
+	state := pm.stateFor(p)
+	state.markChecking()
+
+	transport, err := p.GetTransport()
+	if err != nil {
+		pm.logger.WithError(err).Debugf("Health check: failed to build transport for %s", p.Key())
+		state.recordFailure()
+		return
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   pm.proxyConnectTimeout,
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, pm.proxyConnectTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, pm.ipCheckerURL, nil)
+	if err != nil {
+		state.recordFailure()
+		return
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		pm.logger.Debugf("Health check failed for %s: %v", p.Key(), err)
+		state.recordFailure()
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		state.recordFailure()
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		state.recordFailure()
+		return
+	}
+
+	state.recordSuccess(latency, string(body))
+}
+
+// stateFor returns the ProxyState for p, creating one if this is the first
+// time p has been seen.
+func (pm *ProxyManager) stateFor(p Proxy) *ProxyState {
+	key := p.Key()
+
+	pm.statesMu.RLock()
+	state, ok := pm.states[key]
+	pm.statesMu.RUnlock()
+	if ok {
+		return state
+	}
+
+	pm.statesMu.Lock()
+	defer pm.statesMu.Unlock()
+	if state, ok := pm.states[key]; ok {
+		return state
+	}
+	state = &ProxyState{}
+	pm.states[key] = state
+	return state
+}
+
+// healthyCount returns how many known proxies are currently Healthy.
+func (pm *ProxyManager) healthyCount() int {
+	pm.statesMu.RLock()
+	defer pm.statesMu.RUnlock()
+
+	count := 0
+	for _, s := range pm.states {
+		if s.snapshot().Status == HealthHealthy {
+			count++
+		}
+	}
+	return count
+}
+
+// isHealthy reports whether p is currently usable. A proxy with no recorded
+// state yet is treated as healthy so it can be tried before the first check
+// completes.
+func (pm *ProxyManager) isHealthy(p Proxy) bool {
+	pm.statesMu.RLock()
+	state, ok := pm.states[p.Key()]
+	pm.statesMu.RUnlock()
+	if !ok {
+		return true
+	}
+	return state.snapshot().Status != HealthUnhealthy
+}
+
+// Stats returns a point-in-time snapshot of every known proxy's health, for
+// the dashboard to render.
+func (pm *ProxyManager) Stats() []ProxyStats {
+	pm.mu.RLock()
+	proxies := make([]Proxy, len(pm.proxies))
+	copy(proxies, pm.proxies)
+	pm.mu.RUnlock()
+
+	stats := make([]ProxyStats, 0, len(proxies))
+	for _, p := range proxies {
+		s := pm.stateFor(p).snapshot()
+		stats = append(stats, ProxyStats{
+			Proxy:            p,
+			Status:           s.Status,
+			LastCheck:        s.LastCheck,
+			LastLatency:      s.LastLatency,
+			ExitIP:           s.ExitIP,
+			ConsecutiveFails: s.ConsecutiveFails,
+		})
+	}
+	return stats
+}
+
+// MarkHostUnhealthy records that p should be skipped for requests to host
+// (e.g. after a Cloudflare challenge or a 403 specific to that target),
+// without affecting p's general health.
+func (pm *ProxyManager) MarkHostUnhealthy(p Proxy, host string) {
+	pm.hostSkipMu.Lock()
+	defer pm.hostSkipMu.Unlock()
+
+	if pm.hostSkip[host] == nil {
+		pm.hostSkip[host] = make(map[string]bool)
+	}
+	pm.hostSkip[host][p.Key()] = true
+}
+
+// IsSkippedForHost reports whether p has been marked unhealthy for host.
+func (pm *ProxyManager) IsSkippedForHost(p Proxy, host string) bool {
+	pm.hostSkipMu.RLock()
+	defer pm.hostSkipMu.RUnlock()
+	return pm.hostSkip[host][p.Key()]
+}
+
+// Key returns a stable identifier for a proxy, used to key health state.
+func (p *Proxy) Key() string {
+	return fmt.Sprintf("%s:%s", p.IP, p.Port)
+}
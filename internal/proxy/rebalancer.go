@@ -0,0 +1,205 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultInitialWeight      = 1.0
+	defaultWeightFloor        = 0.1
+	defaultDecayFactor        = 0.5
+	defaultRestoreStep        = 0.1
+	defaultErrorRateThreshold = 0.5
+	defaultErrorDecayAlpha    = 0.2
+	defaultProbeInterval      = 30 * time.Second
+)
+
+// proxyWeight is one proxy's state in the smooth weighted round-robin
+// rebalancer: a decayed error rate driving how its weight moves, plus the
+// scratch "current weight" SWRR needs between picks.
+type proxyWeight struct {
+	mu sync.Mutex
+
+	weight        float64
+	currentWeight float64
+	errorRate     float64
+	lastProbe     time.Time
+}
+
+// rebalancerCandidate is one proxy eligible for a Next() pick, paired with
+// its rebalancer weight state.
+type rebalancerCandidate struct {
+	proxy  Proxy
+	weight *proxyWeight
+}
+
+// weightFor returns the proxyWeight for p, creating one at the initial
+// weight if this is the first time p has been seen.
+func (pm *ProxyManager) weightFor(p Proxy) *proxyWeight {
+	key := p.Key()
+
+	pm.rebalancerMu.RLock()
+	w, ok := pm.weights[key]
+	pm.rebalancerMu.RUnlock()
+	if ok {
+		return w
+	}
+
+	pm.rebalancerMu.Lock()
+	defer pm.rebalancerMu.Unlock()
+	if w, ok := pm.weights[key]; ok {
+		return w
+	}
+	w = &proxyWeight{weight: defaultInitialWeight}
+	pm.weights[key] = w
+	return w
+}
+
+// SetRebalancerConfig overrides the rebalancer's weight floor and probe
+// interval, letting callers wire in --proxy-min-weight / --proxy-probe-interval.
+func (pm *ProxyManager) SetRebalancerConfig(minWeight float64, probeInterval time.Duration) {
+	if minWeight > 0 {
+		pm.weightFloor = minWeight
+	}
+	if probeInterval > 0 {
+		pm.probeInterval = probeInterval
+	}
+}
+
+// RecordOutcome updates p's rebalancer weight from a real traffic result.
+// Successes slowly restore weight toward 1.0; failures push a decayed error
+// rate up, and once that error rate crosses the trip threshold the weight
+// is halved (down to the configured floor) so Next() hands p out less often.
+func (pm *ProxyManager) RecordOutcome(p Proxy, success bool, latency time.Duration) {
+	w := pm.weightFor(p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	indicator := 0.0
+	if !success {
+		indicator = 1.0
+	}
+	w.errorRate = w.errorRate*(1-defaultErrorDecayAlpha) + indicator*defaultErrorDecayAlpha
+
+	floor := pm.weightFloor
+	if floor <= 0 {
+		floor = defaultWeightFloor
+	}
+
+	if success {
+		w.weight += defaultRestoreStep
+		if w.weight > defaultInitialWeight {
+			w.weight = defaultInitialWeight
+		}
+		return
+	}
+
+	if w.errorRate > defaultErrorRateThreshold {
+		w.weight *= defaultDecayFactor
+		if w.weight < floor {
+			w.weight = floor
+		}
+	}
+}
+
+// Next picks a proxy using smooth weighted round-robin over every Healthy,
+// non-cooldown proxy whose circuit breaker isn't Tripped, favoring ones
+// with a higher rebalancer weight. A proxy parked at the weight floor still
+// gets an occasional probe pick once pm.probeInterval has elapsed, so it
+// can work its way back up if it has recovered.
+//
+// A Recovering breaker's limited probe volume is checked, and consumed, at
+// selection time via CircuitBreaker.Allow: if the winning pick's breaker
+// refuses (its probe cap is exhausted), Next() retries the selection over
+// the remaining candidates rather than failing outright.
+func (pm *ProxyManager) Next() (Proxy, error) {
+	pm.mu.RLock()
+	proxies := make([]Proxy, len(pm.proxies))
+	copy(proxies, pm.proxies)
+	pm.mu.RUnlock()
+
+	var eligible []rebalancerCandidate
+
+	for _, p := range proxies {
+		if !pm.isHealthy(p) {
+			continue
+		}
+		if pm.runtimeStatsFor(p).inCooldown() {
+			continue
+		}
+		if pm.breakerFor(p).State() == Tripped {
+			continue
+		}
+		eligible = append(eligible, rebalancerCandidate{proxy: p, weight: pm.weightFor(p)})
+	}
+
+	for attempt := 0; attempt < len(eligible); attempt++ {
+		idx := pm.pickSWRR(eligible)
+		if idx == -1 {
+			break
+		}
+		if pm.breakerFor(eligible[idx].proxy).Allow() {
+			return eligible[idx].proxy, nil
+		}
+		eligible = append(eligible[:idx], eligible[idx+1:]...)
+	}
+
+	return Proxy{}, ErrNoHealthyProxies
+}
+
+// pickSWRR runs one smooth-weighted-round-robin pick over eligible, mutating
+// each candidate's currentWeight, and returns the winning index (-1 if
+// eligible is empty).
+func (pm *ProxyManager) pickSWRR(eligible []rebalancerCandidate) int {
+	if len(eligible) == 0 {
+		return -1
+	}
+
+	probeInterval := pm.probeInterval
+	if probeInterval <= 0 {
+		probeInterval = defaultProbeInterval
+	}
+	floor := pm.weightFloor
+	if floor <= 0 {
+		floor = defaultWeightFloor
+	}
+
+	now := time.Now()
+	var total float64
+	bestIdx := -1
+	var bestCurrent float64
+
+	for i, c := range eligible {
+		c.weight.mu.Lock()
+		effective := c.weight.weight
+		if effective <= floor && now.Sub(c.weight.lastProbe) >= probeInterval {
+			effective = defaultRestoreStep
+			c.weight.lastProbe = now
+		}
+		c.weight.currentWeight += effective
+		total += effective
+		if bestIdx == -1 || c.weight.currentWeight > bestCurrent {
+			bestIdx = i
+			bestCurrent = c.weight.currentWeight
+		}
+		c.weight.mu.Unlock()
+	}
+
+	best := eligible[bestIdx].weight
+	best.mu.Lock()
+	best.currentWeight -= total
+	best.mu.Unlock()
+
+	return bestIdx
+}
+
+// RebalancerWeight returns p's current smoothed weight, for tests and the
+// dashboard.
+func (pm *ProxyManager) RebalancerWeight(p Proxy) float64 {
+	w := pm.weightFor(p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.weight
+}
@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestManager(t *testing.T) *ProxyManager {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewProxyManager(logger)
+}
+
+func TestRebalancerEvictsBadProxyBelowFloor(t *testing.T) {
+	pm := newTestManager(t)
+	pm.SetRebalancerConfig(0.05, time.Hour) // probe interval irrelevant here
+
+	bad := Proxy{IP: "10.0.0.1", Port: "1111"}
+	pm.proxies = append(pm.proxies, bad)
+
+	for i := 0; i < 20; i++ {
+		pm.RecordOutcome(bad, false, 0)
+	}
+
+	if w := pm.RebalancerWeight(bad); w > pm.weightFloor {
+		t.Fatalf("expected weight to decay to the floor %v, got %v", pm.weightFloor, w)
+	}
+}
+
+func TestRebalancerRestoresWeightOnSuccess(t *testing.T) {
+	pm := newTestManager(t)
+	pm.SetRebalancerConfig(0.05, time.Hour)
+
+	recovering := Proxy{IP: "10.0.0.2", Port: "2222"}
+	pm.proxies = append(pm.proxies, recovering)
+
+	for i := 0; i < 20; i++ {
+		pm.RecordOutcome(recovering, false, 0)
+	}
+	if w := pm.RebalancerWeight(recovering); w > pm.weightFloor {
+		t.Fatalf("expected weight to decay to the floor before recovering, got %v", w)
+	}
+
+	for i := 0; i < 5; i++ {
+		pm.RecordOutcome(recovering, true, 0)
+	}
+
+	if w := pm.RebalancerWeight(recovering); w <= pm.weightFloor {
+		t.Fatalf("expected repeated successes to restore weight above the floor, got %v", w)
+	}
+}
+
+func TestNextProbesFloorProxyBackIn(t *testing.T) {
+	pm := newTestManager(t)
+	pm.SetRebalancerConfig(0.05, 10*time.Millisecond)
+
+	good := Proxy{IP: "10.0.0.3", Port: "3333"}
+	parked := Proxy{IP: "10.0.0.4", Port: "4444"}
+	pm.proxies = append(pm.proxies, good, parked)
+
+	for i := 0; i < 20; i++ {
+		pm.RecordOutcome(parked, false, 0)
+	}
+	if w := pm.RebalancerWeight(parked); w > pm.weightFloor {
+		t.Fatalf("expected parked proxy to be at the floor, got %v", w)
+	}
+
+	// Immediately after parking, Next() should almost always prefer good
+	// over parked since parked has no weight advantage and hasn't had time
+	// to be probed.
+	time.Sleep(15 * time.Millisecond)
+
+	var sawParked bool
+	for i := 0; i < 50; i++ {
+		p, err := pm.Next()
+		if err != nil {
+			t.Fatalf("Next() returned error: %v", err)
+		}
+		if p.Key() == parked.Key() {
+			sawParked = true
+			break
+		}
+	}
+
+	if !sawParked {
+		t.Fatalf("expected the parked proxy to be probed back into Next() after the probe interval elapsed")
+	}
+}
@@ -0,0 +1,188 @@
+package proxy
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultBaseCooldown     = 5 * time.Second
+	maxCooldown             = 5 * time.Minute
+)
+
+// runtimeStats tracks how a single proxy has performed on live traffic, as
+// reported by callers via ReportSuccess/ReportFailure. This is distinct from
+// ProxyState, which reflects the background health-checker's own probes.
+type runtimeStats struct {
+	mu sync.Mutex
+
+	successes           uint64
+	failures            uint64
+	consecutiveFailures int
+	cooldownUntil       time.Time
+}
+
+func (s *runtimeStats) recordSuccess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.successes++
+	s.consecutiveFailures = 0
+	s.cooldownUntil = time.Time{}
+}
+
+func (s *runtimeStats) recordFailure(threshold int, baseCooldown time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+	s.consecutiveFailures++
+
+	if s.consecutiveFailures >= threshold {
+		// Exponential backoff keyed off how far past the threshold we are.
+		overage := s.consecutiveFailures - threshold
+		cooldown := baseCooldown << uint(overage)
+		if cooldown > maxCooldown || cooldown <= 0 {
+			cooldown = maxCooldown
+		}
+		s.cooldownUntil = time.Now().Add(cooldown)
+	}
+}
+
+func (s *runtimeStats) inCooldown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.cooldownUntil.IsZero() && time.Now().Before(s.cooldownUntil)
+}
+
+// ProxySnapshot is a read-only view of a proxy's runtime performance, for
+// the dashboard to render.
+type ProxySnapshot struct {
+	Proxy               Proxy
+	Successes           uint64
+	Failures            uint64
+	SuccessRate         float64
+	ConsecutiveFailures int
+	CooldownUntil       time.Time
+	CircuitState        CircuitBreakerState
+}
+
+func (pm *ProxyManager) runtimeStatsFor(p Proxy) *runtimeStats {
+	key := p.Key()
+
+	pm.runtimeMu.RLock()
+	s, ok := pm.runtime[key]
+	pm.runtimeMu.RUnlock()
+	if ok {
+		return s
+	}
+
+	pm.runtimeMu.Lock()
+	defer pm.runtimeMu.Unlock()
+	if s, ok := pm.runtime[key]; ok {
+		return s
+	}
+	s = &runtimeStats{}
+	pm.runtime[key] = s
+	return s
+}
+
+// GetNextProxy dispenses proxies in round-robin order using an atomic
+// cursor, skipping any proxy that is unhealthy, currently in its failure
+// cooldown window, or has a Tripped circuit breaker. It returns
+// ErrNoHealthyProxies if none are eligible.
+func (pm *ProxyManager) GetNextProxy() (Proxy, error) {
+	pm.mu.RLock()
+	proxies := make([]Proxy, len(pm.proxies))
+	copy(proxies, pm.proxies)
+	pm.mu.RUnlock()
+
+	if len(proxies) == 0 {
+		return Proxy{}, ErrNoHealthyProxies
+	}
+
+	start := atomic.AddUint64(&pm.cursor, 1)
+	for i := 0; i < len(proxies); i++ {
+		p := proxies[(int(start)+i)%len(proxies)]
+		if !pm.isHealthy(p) {
+			continue
+		}
+		if pm.runtimeStatsFor(p).inCooldown() {
+			continue
+		}
+		if !pm.breakerFor(p).Allow() {
+			continue
+		}
+		return p, nil
+	}
+
+	return Proxy{}, ErrNoHealthyProxies
+}
+
+// ReportSuccess records that a request through p succeeded in latency,
+// clearing any cooldown, resetting its consecutive-failure count, and
+// feeding both the rebalancer's weight and the circuit breaker for p.
+func (pm *ProxyManager) ReportSuccess(p Proxy, latency time.Duration) {
+	pm.runtimeStatsFor(p).recordSuccess()
+	pm.RecordOutcome(p, true, latency)
+	pm.breakerFor(p).RecordOutcome(true, latency)
+}
+
+// ReportFailure records that a request through p failed after latency (0 if
+// the call never completed, e.g. a dial error). Once consecutive failures
+// exceed the configured threshold, the proxy is skipped by GetNextProxy for
+// an exponentially increasing cooldown window. It also feeds the rebalancer
+// and circuit breaker so Next() hands p out less, or not at all.
+func (pm *ProxyManager) ReportFailure(p Proxy, latency time.Duration, err error) {
+	threshold := pm.failureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	cooldown := pm.baseCooldown
+	if cooldown <= 0 {
+		cooldown = defaultBaseCooldown
+	}
+
+	pm.runtimeStatsFor(p).recordFailure(threshold, cooldown)
+	pm.RecordOutcome(p, false, latency)
+	pm.breakerFor(p).RecordOutcome(false, latency)
+	if err != nil {
+		pm.logger.WithError(err).Debugf("Recorded failure for proxy %s", p.Key())
+	}
+}
+
+// Snapshot returns a point-in-time view of every known proxy's runtime
+// performance, for the dashboard to render.
+func (pm *ProxyManager) Snapshot() []ProxySnapshot {
+	pm.mu.RLock()
+	proxies := make([]Proxy, len(pm.proxies))
+	copy(proxies, pm.proxies)
+	pm.mu.RUnlock()
+
+	snapshot := make([]ProxySnapshot, 0, len(proxies))
+	for _, p := range proxies {
+		s := pm.runtimeStatsFor(p)
+		s.mu.Lock()
+		successes, failures := s.successes, s.failures
+		consecutive := s.consecutiveFailures
+		cooldownUntil := s.cooldownUntil
+		s.mu.Unlock()
+
+		total := successes + failures
+		var rate float64
+		if total > 0 {
+			rate = float64(successes) / float64(total) * 100
+		}
+
+		snapshot = append(snapshot, ProxySnapshot{
+			Proxy:               p,
+			Successes:           successes,
+			Failures:            failures,
+			SuccessRate:         rate,
+			ConsecutiveFailures: consecutive,
+			CooldownUntil:       cooldownUntil,
+			CircuitState:        pm.CircuitState(p),
+		})
+	}
+	return snapshot
+}
@@ -0,0 +1,35 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/proxy"
+)
+
+// socks5Transport builds an *http.Transport that dials through this proxy
+// using the SOCKS5 protocol, for sellers that don't offer HTTP CONNECT.
+func (p *Proxy) socks5Transport() (*http.Transport, error) {
+	var auth *proxy.Auth
+	if p.Username != "" || p.Password != "" {
+		auth = &proxy.Auth{
+			User:     p.Username,
+			Password: p.Password,
+		}
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", net.JoinHostPort(p.IP, p.Port), auth, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SOCKS5 dialer: %w", err)
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.Dial(network, addr)
+		},
+	}
+
+	return transport, nil
+}
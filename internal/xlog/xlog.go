@@ -0,0 +1,83 @@
+// Package xlog wraps logrus so request-scoped fields — which connection,
+// which channel, which proxy, which retry attempt a log line came from —
+// ride along on a context.Context instead of being threaded through every
+// function signature by hand.
+package xlog
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is a logrus.Entry with convenience methods for the fields this bot
+// cares about, plus Spawn for deriving a child with one more field set.
+type Logger struct {
+	*logrus.Entry
+}
+
+// New wraps base in a Logger with no fields set yet.
+func New(base *logrus.Logger) *Logger {
+	return &Logger{Entry: logrus.NewEntry(base)}
+}
+
+// Spawn returns a child Logger with key=value added to its fields, leaving
+// the receiver untouched. It's the general-purpose building block the
+// WithXxx helpers below are written in terms of.
+func (l *Logger) Spawn(key string, value interface{}) *Logger {
+	return &Logger{Entry: l.Entry.WithField(key, value)}
+}
+
+// WithConnID returns a child Logger tagging log lines with the connection
+// index they belong to.
+func (l *Logger) WithConnID(connID int) *Logger {
+	return l.Spawn("conn_id", connID)
+}
+
+// WithChannelID returns a child Logger tagging log lines with a channel ID.
+func (l *Logger) WithChannelID(channelID int) *Logger {
+	return l.Spawn("channel_id", channelID)
+}
+
+// WithChannelName returns a child Logger tagging log lines with a channel name.
+func (l *Logger) WithChannelName(channelName string) *Logger {
+	return l.Spawn("channel_name", channelName)
+}
+
+// WithProxy returns a child Logger tagging log lines with the proxy key (see
+// proxy.Proxy.Key) a request went through.
+func (l *Logger) WithProxy(proxyKey string) *Logger {
+	return l.Spawn("proxy", proxyKey)
+}
+
+// WithAttempt returns a child Logger tagging log lines with a retry attempt
+// number.
+func (l *Logger) WithAttempt(attempt int) *Logger {
+	return l.Spawn("attempt", attempt)
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying xl, retrievable with FromContext.
+func NewContext(ctx context.Context, xl *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, xl)
+}
+
+// FromContext returns the Logger stashed in ctx by NewContext, or a bare
+// Logger over logrus's standard logger if ctx doesn't carry one.
+func FromContext(ctx context.Context) *Logger {
+	return FromContextOrDefault(ctx, logrus.StandardLogger())
+}
+
+// FromContextOrDefault returns the Logger stashed in ctx by NewContext, or a
+// bare Logger over base if ctx doesn't carry one. Callers that already hold
+// a *logrus.Logger (e.g. a constructor's logger parameter) should prefer
+// this over FromContext so a caller who forgets to wire xlog into ctx still
+// gets that logger's configured level/output instead of silently falling
+// back to logrus's standard logger.
+func FromContextOrDefault(ctx context.Context, base *logrus.Logger) *Logger {
+	if xl, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return xl
+	}
+	return New(base)
+}